@@ -3,7 +3,12 @@
 // license that can be found in the LICENSE file.
 package ast
 
-import "github.com/hulo-io/vbsparser/token"
+import (
+	"strings"
+
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/hulo-io/vbsparser/vbsconv"
+)
 
 type Node interface {
 	Pos() token.Pos
@@ -25,21 +30,47 @@ type Expr interface {
 	exprNode()
 }
 
+// A CommentGroup represents a sequence of comments
+// with no other tokens and no empty lines between.
 type CommentGroup struct {
-	List []*Comment
+	List []*Comment // len(List) > 0
 }
 
 func (g *CommentGroup) Pos() token.Pos { return g.List[0].Pos() }
 func (g *CommentGroup) End() token.Pos { return g.List[len(g.List)-1].End() }
 
+// Text returns the text of the comment group without the comment
+// markers and a single leading blank of each comment; the comments
+// are separated by newlines.
+func (g *CommentGroup) Text() string {
+	if g == nil {
+		return ""
+	}
+	lines := make([]string, 0, len(g.List))
+	for _, c := range g.List {
+		lines = append(lines, strings.TrimRight(strings.TrimPrefix(c.Text, " "), " \t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// A Comment node represents a single ' or Rem comment.
 type Comment struct {
-	TokPos token.Pos
-	Tok    token.Token // ' or Rem
-	Text   string
+	TokPos token.Pos   // position of the comment marker
+	Tok    token.Token // token.APOSTROPHE or token.REM
+	Text   string      // comment text following the marker
 }
 
 func (c *Comment) Pos() token.Pos { return c.TokPos }
-func (c *Comment) End() token.Pos { return token.Pos(int(c.TokPos) + len(c.Text)) }
+func (c *Comment) End() token.Pos {
+	return token.Pos(int(c.TokPos) + len(c.Tok.String()) + len(c.Text))
+}
+
+// A Sep represents the terminator of a statement: a token.COLON if the
+// next statement follows on the same line, or a token.NEWLINE.
+type Sep struct {
+	TokPos token.Pos   // position of Tok
+	Tok    token.Token // token.COLON or token.NEWLINE
+}
 
 type Modifier int
 
@@ -55,6 +86,10 @@ func (m Modifier) HasPrivate() bool {
 	return m&M_PRIVATE != 0
 }
 
+func (m Modifier) HasDefault() bool {
+	return m&M_DEFAULT != 0
+}
+
 func (m Modifier) IsAll() bool {
 	return m == M_ALL
 }
@@ -63,76 +98,110 @@ const (
 	M_NONE   = 0
 	M_PUBLIC = 1 << iota
 	M_PRIVATE
-	M_ALL = M_PUBLIC | M_PRIVATE
+	M_DEFAULT // Public Default, the default member of a class
+	M_ALL     = M_PUBLIC | M_PRIVATE
 )
 
 // ----------------------------------------------------------------------------
 // Declarations
 
 type (
+	// A BadDecl node is a placeholder for a declaration containing
+	// syntax errors for which a correct declaration node cannot be
+	// created.
+	BadDecl struct {
+		From, To token.Pos // position range of bad declaration
+	}
+
 	// A SubDecl node represents a sub declaration.
 	SubDecl struct {
+		Doc    *CommentGroup // associated documentation; or nil
 		Mod    Modifier
 		ModPos token.Pos
 		Sub    token.Pos // position of "Sub"
 		Name   *Ident
+		Lparen token.Pos // position of "("; or NoPos
 		Recv   []*Field
+		Rparen token.Pos // position of ")"; or NoPos
 		Body   *BlockStmt
 		EndSub token.Pos // position of "End Sub"
 	}
 
 	// A FuncDecl node represents a function declaration.
 	FuncDecl struct {
+		Doc      *CommentGroup // associated documentation; or nil
 		Mod      Modifier
 		ModPos   token.Pos
 		Function token.Pos // position of "Function"
 		Name     *Ident
+		Lparen   token.Pos // position of "("; or NoPos
 		Recv     []*Field
+		Rparen   token.Pos // position of ")"; or NoPos
 		Body     *BlockStmt
 		EndFunc  token.Pos // position of "End Function"
 	}
 
 	// A PropertyDecl node represents a property declaration.
 	PropertyDecl struct {
+		Doc         *CommentGroup // associated documentation; or nil
 		Mod         Modifier
 		ModPos      token.Pos
 		Property    token.Pos   // position of "Property"
 		Tok         token.Token // Token.LET | Token.GET | Token.SET
 		TokPos      token.Pos
 		Name        *Ident
-		Recv        []*Field
+		Lparen      token.Pos // position of "("; or NoPos
+		Recv        []*Field  // for Let and Set, the last one receives the value
+		Rparen      token.Pos // position of ")"; or NoPos
 		Body        *BlockStmt
 		EndProverty token.Pos // position of "End Property"
 	}
 
 	// A ClassDecl node represents a class declaration.
 	ClassDecl struct {
-		Mod    Modifier
-		ModPos token.Pos
-		Class  token.Pos // position of "Class"
-		Name   *Ident
-		// dim, func, member, proverpty, assign
-		Stmts    []Stmt
-		Decls    []Decl
+		Doc      *CommentGroup // associated documentation; or nil
+		Mod      Modifier
+		ModPos   token.Pos
+		Class    token.Pos // position of "Class"
+		Name     *Ident
+		Decls    []Decl    // variables, constants, procedures and properties
 		EndClass token.Pos // position of "End Class"
 	}
 
 	// A DimDecl node represents an dim declaration.
 	DimDecl struct {
 		Dim   token.Pos // position of "Dim"
-		List  []Expr
-		Colon token.Pos // position of ":"
-		Set   *AssignStmt
+		Specs []*VarSpec
 	}
 
 	// A ReDimDecl node represents a redim declaration.
 	ReDimDecl struct {
 		ReDim    token.Pos // position of "ReDim"
 		Preserve token.Pos // position of "Preserve"
-		List     []Expr
+		Specs    []*VarSpec
+	}
+
+	// A VarDecl node represents a Public or Private variable
+	// declaration, such as Private a, b(10).
+	VarDecl struct {
+		Doc    *CommentGroup // associated documentation; or nil
+		Mod    Modifier
+		ModPos token.Pos // position of "Public" or "Private"
+		Specs  []*VarSpec
+	}
+
+	// A ConstDecl node represents a constant declaration, such as
+	// Public Const A = 1, B = "x".
+	ConstDecl struct {
+		Doc    *CommentGroup // associated documentation; or nil
+		Mod    Modifier
+		ModPos token.Pos
+		Const  token.Pos // position of "Const"
+		Specs  []*ValueSpec
 	}
 )
 
+func (d *BadDecl) Pos() token.Pos { return d.From }
 func (d *SubDecl) Pos() token.Pos {
 	if d.Mod.HasPublic() {
 		return d.ModPos
@@ -171,31 +240,108 @@ func (d *ClassDecl) Pos() token.Pos {
 }
 func (s *DimDecl) Pos() token.Pos   { return s.Dim }
 func (s *ReDimDecl) Pos() token.Pos { return s.ReDim }
+func (d *VarDecl) Pos() token.Pos   { return d.ModPos }
+func (d *ConstDecl) Pos() token.Pos {
+	if !d.Mod.IsNone() {
+		return d.ModPos
+	}
+	return d.Const
+}
 
+func (d *BadDecl) End() token.Pos      { return d.To }
 func (d *SubDecl) End() token.Pos      { return d.EndSub }
 func (d *PropertyDecl) End() token.Pos { return d.EndProverty }
 func (d *FuncDecl) End() token.Pos     { return d.EndFunc }
 func (d *ClassDecl) End() token.Pos    { return d.EndClass }
-func (d *DimDecl) End() token.Pos      { return d.List[len(d.List)-1].End() }
-func (d *ReDimDecl) End() token.Pos    { return d.List[len(d.List)-1].End() }
+func (d *DimDecl) End() token.Pos      { return d.Specs[len(d.Specs)-1].End() }
+func (d *ReDimDecl) End() token.Pos    { return d.Specs[len(d.Specs)-1].End() }
+func (d *VarDecl) End() token.Pos      { return d.Specs[len(d.Specs)-1].End() }
+func (d *ConstDecl) End() token.Pos    { return d.Specs[len(d.Specs)-1].End() }
 
+func (*BadDecl) declNode()      {}
 func (*SubDecl) declNode()      {}
 func (*PropertyDecl) declNode() {}
 func (*FuncDecl) declNode()     {}
 func (*ClassDecl) declNode()    {}
 func (*DimDecl) declNode()      {}
 func (*ReDimDecl) declNode()    {}
+func (*VarDecl) declNode()      {}
+func (*ConstDecl) declNode()    {}
 
+// Value returns the parameter that receives the assigned value of a
+// Property Let or Property Set procedure, or nil.
+func (d *PropertyDecl) Value() *Field {
+	if d.Tok == token.GET || len(d.Recv) == 0 {
+		return nil
+	}
+	return d.Recv[len(d.Recv)-1]
+}
+
+// A Field represents a parameter in a parameter list.
 type Field struct {
 	TokPos token.Pos
 	Tok    token.Token // Token.BYVAL | Token.BYREF
 	Name   *Ident
+	Lparen token.Pos // position of "(" of an array parameter, such as a(); or NoPos
+	Rparen token.Pos // position of ")"; or NoPos
+}
+
+func (f *Field) Pos() token.Pos {
+	if f.TokPos.IsValid() {
+		return f.TokPos
+	}
+	return f.Name.Pos()
+}
+func (f *Field) End() token.Pos {
+	if f.Rparen.IsValid() {
+		return f.Rparen + 1
+	}
+	return f.Name.End()
 }
 
+// IsArray reports whether f is an array parameter, such as a().
+func (f *Field) IsArray() bool { return f.Lparen.IsValid() }
+
+// A VarSpec represents a variable in a Dim, ReDim, Public or Private
+// declaration: a scalar such as x, an array with the upper bounds of its
+// dimensions such as a(9, 3), or a dynamic array such as d().
+type VarSpec struct {
+	Name    *Ident
+	Lparen  token.Pos // position of "("; or NoPos
+	Bounds  []Expr    // upper bounds of the dimensions; or nil
+	Rparen  token.Pos // position of ")"; or NoPos
+	Dynamic bool      // set for a dynamic array, declared without bounds
+}
+
+func (s *VarSpec) Pos() token.Pos { return s.Name.Pos() }
+func (s *VarSpec) End() token.Pos {
+	if s.Rparen.IsValid() {
+		return s.Rparen + 1
+	}
+	return s.Name.End()
+}
+
+// A ValueSpec represents a constant in a Const declaration.
+type ValueSpec struct {
+	Name   *Ident
+	Assign token.Pos // position of "="
+	Value  Expr
+}
+
+func (s *ValueSpec) Pos() token.Pos { return s.Name.Pos() }
+func (s *ValueSpec) End() token.Pos { return s.Value.End() }
+
 // ----------------------------------------------------------------------------
 // Statement
 
 type (
+	// A BadStmt node is a placeholder for statements containing
+	// syntax errors for which no correct statement nodes can be
+	// created.
+	BadStmt struct {
+		From, To token.Pos // position range of bad statement
+	}
+
 	// An OptionStmt node represents an option statement.
 	OptionStmt struct {
 		Option   token.Pos // position of "Option"
@@ -205,6 +351,7 @@ type (
 	// A RandomizeStmt node represents a randomize statement.
 	RandomizeStmt struct {
 		Randomize token.Pos // position of "Randomize"
+		Seed      Expr      // seed value; or nil
 	}
 
 	// A WithStmt node represents a with statement.
@@ -217,7 +364,7 @@ type (
 
 	// An AssignStmt node represents an assign statement.
 	AssignStmt struct {
-		Tok    token.Token // Token.SET | Token.CONST
+		Tok    token.Token // Token.SET | Token.LET, or Token.ILLEGAL
 		TokPos token.Pos   // position of Tok
 		Lhs    Expr
 		Assign token.Pos // position of '='
@@ -229,59 +376,90 @@ type (
 		Stop token.Pos // position of "Stop"
 	}
 
+	// An EraseStmt node represents an erase statement.
+	EraseStmt struct {
+		Erase token.Pos // position of "Erase"
+		List  []Expr    // arrays to erase
+	}
+
+	// An ExecuteStmt node represents an Execute or ExecuteGlobal
+	// statement.
+	ExecuteStmt struct {
+		Execute token.Pos // position of "Execute" or "ExecuteGlobal"
+		Global  bool      // set for ExecuteGlobal
+		X       Expr      // the code to execute
+	}
+
 	// A SelectStmt node represents a select statement.
 	SelectStmt struct {
 		Select    token.Pos // position of "Select"
 		Var       Expr
 		Cases     []*CaseStmt
-		Else      *CaseStmt
-		EndSelect token.Pos // position of "End Select"
+		Else      *CaseElseStmt // Case Else clause; or nil
+		EndSelect token.Pos     // position of "End Select"
 	}
 
-	// A CaseStmt node represents a case statement.
+	// A CaseStmt node represents a case clause of a select statement,
+	// such as Case 1, 2, 3.
 	CaseStmt struct {
 		Case token.Pos // position of "Case"
-		Cond Expr
+		List []Expr    // values to compare with
 		Body *BlockStmt
 	}
 
-	// An IfStmt node represents an if statement.
+	// A CaseElseStmt node represents the Case Else clause of a select
+	// statement.
+	CaseElseStmt struct {
+		Case token.Pos // position of "Case"
+		Else token.Pos // position of "Else"
+		Body *BlockStmt
+	}
+
+	// An IfStmt node represents an if statement. In the single-line
+	// form, such as If x Then a = 1 : b = 2 Else c = 3, the statements
+	// of Body and Else are separated by colons, ElseIf is empty and
+	// there is no End If.
 	IfStmt struct {
-		If     token.Pos // position of "If"
-		Cond   Expr
-		Then   token.Pos // position of "Then"
-		Body   *BlockStmt
-		ElseIf []*IfStmt
-		Else   *BlockStmt
-		EndIf  token.Pos // position of "End If"
+		If         token.Pos // position of "If"
+		Cond       Expr
+		Then       token.Pos // position of "Then"
+		Body       *BlockStmt
+		ElseIf     []*IfStmt
+		ElsePos    token.Pos // position of "Else"; or NoPos
+		Else       *BlockStmt
+		EndIf      token.Pos // position of "End If"; NoPos for the single-line form
+		SingleLine bool      // set for the single-line form
 	}
 
 	// A BlockStmt node represents a block statement.
 	BlockStmt struct {
 		List []Stmt
+		Seps []Sep // Seps[i] terminates List[i]; missing entries stand for a newline
 	}
 
 	// A CallStmt node represents a call statement.
 	CallStmt struct {
 		Call token.Pos // position of "Call"
-		Name *Ident
+		Name Expr      // procedure, e.g. Foo or obj.Method
 		Recv []Expr
 	}
 
 	// An ExitStmt node represents an exit statement.
 	ExitStmt struct {
 		Exit token.Pos   // position of "Exit"
+		XPos token.Pos   // position of X
 		X    token.Token // Token.Do | For | Function | Property | Sub
 	}
 
 	// A ForNextStmt node represents a For..Next statement.
 	ForNextStmt struct {
 		For     token.Pos // position of "For"
-		Start   Expr
+		Start   Expr      // counter initialization, a BinaryExpr with Op token.EQ
 		To      token.Pos // position of "To"
 		End_    Expr
 		StepPos token.Pos // position of "Step"
 		Step    Expr
+		Sep     Sep // terminator of the header; the zero Sep stands for a newline
 		Body    *BlockStmt
 		Next    token.Pos // position of "Next"
 	}
@@ -293,6 +471,7 @@ type (
 		Elem  Expr
 		In    token.Pos // position of "In"
 		Group Expr
+		Sep   Sep // terminator of the header; the zero Sep stands for a newline
 		Body  *BlockStmt
 		Next  token.Pos // position of "Next"
 		Stmt  Stmt
@@ -313,6 +492,7 @@ type (
 		Tok    token.Token // Token.WHILE | Token.UNTIL
 		TokPos token.Pos
 		Cond   Expr
+		Sep    Sep // terminator of the header; the zero Sep stands for a newline
 		Body   *BlockStmt
 		Loop   token.Pos // position of "Loop"
 	}
@@ -335,20 +515,21 @@ type (
 		Zero token.Pos
 	}
 
-	MemberStmt struct {
-		Mod    Modifier // public or private
-		ModPos token.Pos
-		Name   *Ident
-	}
-
 	// An ExprStmt node represents a (stand-alone) expression
 	// in a statement list.
 	ExprStmt struct {
-		Doc *CommentGroup
-		X   Expr // expression
+		Doc *CommentGroup // associated documentation; or nil
+		X   Expr          // expression
+	}
+
+	// A DeclStmt node represents a Dim, ReDim, Public, Private or
+	// Const declaration in a statement list.
+	DeclStmt struct {
+		Decl Decl // *DimDecl, *ReDimDecl, *VarDecl or *ConstDecl
 	}
 )
 
+func (s *BadStmt) Pos() token.Pos       { return s.From }
 func (s *OptionStmt) Pos() token.Pos    { return s.Option }
 func (s *RandomizeStmt) Pos() token.Pos { return s.Randomize }
 func (s *WithStmt) Pos() token.Pos      { return s.With }
@@ -358,9 +539,13 @@ func (s *AssignStmt) Pos() token.Pos {
 	}
 	return s.Lhs.Pos()
 }
-func (s *StopStmt) Pos() token.Pos   { return s.Stop }
-func (s *SelectStmt) Pos() token.Pos { return s.Select }
-func (s *IfStmt) Pos() token.Pos     { return s.If }
+func (s *StopStmt) Pos() token.Pos     { return s.Stop }
+func (s *EraseStmt) Pos() token.Pos    { return s.Erase }
+func (s *ExecuteStmt) Pos() token.Pos  { return s.Execute }
+func (s *SelectStmt) Pos() token.Pos   { return s.Select }
+func (s *CaseStmt) Pos() token.Pos     { return s.Case }
+func (s *CaseElseStmt) Pos() token.Pos { return s.Case }
+func (s *IfStmt) Pos() token.Pos       { return s.If }
 func (s *BlockStmt) Pos() token.Pos {
 	if len(s.List) > 0 {
 		return s.List[0].Pos()
@@ -373,22 +558,59 @@ func (s *ForNextStmt) Pos() token.Pos   { return s.For }
 func (s *ForEachStmt) Pos() token.Pos   { return s.For }
 func (s *WhileWendStmt) Pos() token.Pos { return s.While }
 func (s *DoLoopStmt) Pos() token.Pos    { return s.Do }
-func (s *OnErrorStmt) Pos() token.Pos   { return s.Error }
-func (s *MemberStmt) Pos() token.Pos {
-	if !s.Mod.IsNone() {
-		return s.ModPos
+func (s *OnErrorStmt) Pos() token.Pos   { return s.On }
+func (s *ExprStmt) Pos() token.Pos      { return s.X.Pos() }
+func (s *DeclStmt) Pos() token.Pos      { return s.Decl.Pos() }
+
+func (s *BadStmt) End() token.Pos    { return s.To }
+func (s *OptionStmt) End() token.Pos { return s.Explicit }
+func (s *RandomizeStmt) End() token.Pos {
+	if s.Seed != nil {
+		return s.Seed.End()
 	}
-	return s.Name.End()
+	return token.Pos(int(s.Randomize) + len("Randomize"))
+}
+func (s *WithStmt) End() token.Pos   { return s.EndWith }
+func (s *AssignStmt) End() token.Pos { return s.Rhs.End() }
+func (s *StopStmt) End() token.Pos   { return s.Stop }
+func (s *EraseStmt) End() token.Pos {
+	if len(s.List) > 0 {
+		return s.List[len(s.List)-1].End()
+	}
+	return token.Pos(int(s.Erase) + len("Erase"))
+}
+func (s *ExecuteStmt) End() token.Pos { return s.X.End() }
+func (s *SelectStmt) End() token.Pos  { return s.EndSelect }
+func (s *CaseStmt) End() token.Pos {
+	if s.Body != nil && len(s.Body.List) > 0 {
+		return s.Body.End()
+	}
+	if len(s.List) > 0 {
+		return s.List[len(s.List)-1].End()
+	}
+	return token.Pos(int(s.Case) + len("Case"))
+}
+func (s *CaseElseStmt) End() token.Pos {
+	if s.Body != nil && len(s.Body.List) > 0 {
+		return s.Body.End()
+	}
+	return token.Pos(int(s.Else) + len("Else"))
+}
+func (s *IfStmt) End() token.Pos {
+	if !s.SingleLine {
+		return s.EndIf
+	}
+	if s.Else != nil && len(s.Else.List) > 0 {
+		return s.Else.End()
+	}
+	if s.ElsePos.IsValid() {
+		return token.Pos(int(s.ElsePos) + len("Else"))
+	}
+	if len(s.Body.List) > 0 {
+		return s.Body.End()
+	}
+	return token.Pos(int(s.Then) + len("Then"))
 }
-func (s *ExprStmt) Pos() token.Pos { return s.X.Pos() }
-
-func (s *OptionStmt) End() token.Pos    { return s.Explicit }
-func (s *RandomizeStmt) End() token.Pos { return s.Randomize }
-func (s *WithStmt) End() token.Pos      { return s.EndWith }
-func (s *AssignStmt) End() token.Pos    { return s.Rhs.End() }
-func (s *StopStmt) End() token.Pos      { return s.Stop }
-func (s *SelectStmt) End() token.Pos    { return s.EndSelect }
-func (s *IfStmt) End() token.Pos        { return s.EndIf }
 func (s *BlockStmt) End() token.Pos {
 	if len(s.List) > 0 {
 		return s.List[len(s.List)-1].End()
@@ -401,7 +623,12 @@ func (s *CallStmt) End() token.Pos {
 	}
 	return s.Name.End()
 }
-func (s *ExitStmt) End() token.Pos    { return token.Pos(int(s.Exit) + len(s.X)) }
+func (s *ExitStmt) End() token.Pos {
+	if s.XPos.IsValid() {
+		return token.Pos(int(s.XPos) + len(s.X.String()))
+	}
+	return token.Pos(int(s.Exit) + len("Exit ") + len(s.X.String()))
+}
 func (s *ForNextStmt) End() token.Pos { return s.Next }
 func (s *ForEachStmt) End() token.Pos {
 	if s.Stmt != nil {
@@ -410,7 +637,12 @@ func (s *ForEachStmt) End() token.Pos {
 	return s.Next
 }
 func (s *WhileWendStmt) End() token.Pos { return s.Wend }
-func (s *DoLoopStmt) End() token.Pos    { return s.Loop }
+func (s *DoLoopStmt) End() token.Pos {
+	if !s.Pre && s.Cond != nil {
+		return s.Cond.End()
+	}
+	return s.Loop
+}
 func (s *OnErrorStmt) End() token.Pos {
 	if s.OnErrorGoto != nil {
 		return s.OnErrorGoto.Zero
@@ -420,15 +652,20 @@ func (s *OnErrorStmt) End() token.Pos {
 	}
 	return token.NoPos
 }
-func (s *MemberStmt) End() token.Pos { return s.Name.Pos() }
-func (s *ExprStmt) End() token.Pos   { return s.X.End() }
+func (s *ExprStmt) End() token.Pos { return s.X.End() }
+func (s *DeclStmt) End() token.Pos { return s.Decl.End() }
 
+func (*BadStmt) stmtNode()       {}
 func (*OptionStmt) stmtNode()    {}
 func (*RandomizeStmt) stmtNode() {}
 func (*WithStmt) stmtNode()      {}
 func (*AssignStmt) stmtNode()    {}
 func (*StopStmt) stmtNode()      {}
+func (*EraseStmt) stmtNode()     {}
+func (*ExecuteStmt) stmtNode()   {}
 func (*SelectStmt) stmtNode()    {}
+func (*CaseStmt) stmtNode()      {}
+func (*CaseElseStmt) stmtNode()  {}
 func (*IfStmt) stmtNode()        {}
 func (*BlockStmt) stmtNode()     {}
 func (*CallStmt) stmtNode()      {}
@@ -438,24 +675,39 @@ func (*ForEachStmt) stmtNode()   {}
 func (*WhileWendStmt) stmtNode() {}
 func (*DoLoopStmt) stmtNode()    {}
 func (*OnErrorStmt) stmtNode()   {}
-func (*MemberStmt) stmtNode()    {}
 func (*ExprStmt) stmtNode()      {}
+func (*DeclStmt) stmtNode()      {}
 
 // ----------------------------------------------------------------------------
 // Expression
 
 type (
+	// A BadExpr node is a placeholder for an expression containing
+	// syntax errors for which a correct expression node cannot be
+	// created.
+	BadExpr struct {
+		From, To token.Pos // position range of bad expression
+	}
+
+	// An OmittedExpr node stands for an argument left out of an argument
+	// list, such as the second one in MsgBox "Hello", , "Title".
+	OmittedExpr struct {
+		At token.Pos // position of the "," or ")" following the omitted argument, or of the end of the statement
+	}
+
 	// A BasicLit node represents a literal of basic type.
 	BasicLit struct {
-		Kind     token.Token // Token.Empty | Token.Null | Token.Boolean | Token.Byte | Token.Integer | Token.Currency | Token.Long | Token.Single | Token.Double | Token.Date | Token.String | Token.Object | Token.Error
-		Value    string
-		ValuePos token.Pos // literal position
+		Kind     token.Token // Token.Empty | Token.Null | Token.Nothing | Token.Boolean | Token.Byte | Token.Integer | Token.Currency | Token.Long | Token.Single | Token.Double | Token.Date | Token.String | Token.Object | Token.Error
+		Value    string      // literal string; e.g. 42, &HFF, 1.5E+10, #10/16/2026#; strings are stored unquoted
+		ValuePos token.Pos   // literal position
+		ValueEnd token.Pos   // position after the literal if the source is not the quoted Value, as for the HTML of an ASP page; or NoPos
 	}
 
 	// An Ident node represents an identifier.
 	Ident struct {
-		NamePos token.Pos // identifier position
-		Name    string    // identifier name
+		NamePos   token.Pos // identifier position
+		Name      string    // identifier name, without brackets
+		Bracketed bool      // set if the identifier was escaped as [Name]
 	}
 
 	// An IndexExpr node represents an expression followed by an index.
@@ -481,19 +733,44 @@ type (
 	}
 
 	// A CallExpr node represents an expression followed by an argument list.
+	// In a call statement without Call, such as MsgBox "hi", vbOKOnly, the
+	// arguments are not parenthesized: Stmt is set, and Lparen and Rparen
+	// are NoPos. A single parenthesized argument such as x in Foo (x) is
+	// a ParenExpr then, as it is passed by value.
+	//
+	// Eval marks a call of the built-in Eval function, which evaluates
+	// its argument as code at run time.
 	CallExpr struct {
 		Func   Expr
-		Lparen token.Pos // position of "("
+		Lparen token.Pos // position of "("; or NoPos
 		Recv   []Expr
+		Rparen token.Pos // position of ")"; or NoPos
+		Stmt   bool      // set for a call statement without parentheses
+		Eval   bool      // set for a call of Eval
+	}
+
+	// A ParenExpr node represents a parenthesized expression.
+	ParenExpr struct {
+		Lparen token.Pos // position of "("
+		X      Expr      // parenthesized expression
 		Rparen token.Pos // position of ")"
 	}
 
 	// A SelectorExpr node represents an expression followed by a selector.
+	// Inside a With statement, X is nil for a selector such as .Name that
+	// refers to the object of the With statement.
 	SelectorExpr struct {
-		X   Expr   // expression
+		X   Expr   // expression; or nil
 		Sel *Ident // field selector
 	}
 
+	// A UnaryExpr node represents a unary expression.
+	UnaryExpr struct {
+		OpPos token.Pos   // position of Op
+		Op    token.Token // operator: Token.NOT, Token.SUB or Token.ADD
+		X     Expr        // operand
+	}
+
 	// A BinaryExpr node represents a binary expression.
 	BinaryExpr struct {
 		X     Expr        // left operand
@@ -503,46 +780,79 @@ type (
 	}
 )
 
+func (x *BadExpr) Pos() token.Pos       { return x.From }
+func (x *OmittedExpr) Pos() token.Pos   { return x.At }
 func (x *Ident) Pos() token.Pos         { return x.NamePos }
 func (x *CallExpr) Pos() token.Pos      { return x.Func.Pos() }
 func (x *IndexExpr) Pos() token.Pos     { return x.X.Pos() }
 func (x *IndexListExpr) Pos() token.Pos { return x.X.Pos() }
 func (x *NewExpr) Pos() token.Pos       { return x.New }
-func (x *SelectorExpr) Pos() token.Pos  { return x.X.Pos() }
-func (x *BinaryExpr) Pos() token.Pos    { return x.X.Pos() }
-func (x *BasicLit) Pos() token.Pos      { return x.ValuePos }
+func (x *ParenExpr) Pos() token.Pos     { return x.Lparen }
+func (x *SelectorExpr) Pos() token.Pos {
+	if x.X == nil {
+		return x.Sel.Pos() - 1 // position of the leading '.'
+	}
+	return x.X.Pos()
+}
+func (x *UnaryExpr) Pos() token.Pos  { return x.OpPos }
+func (x *BinaryExpr) Pos() token.Pos { return x.X.Pos() }
+func (x *BasicLit) Pos() token.Pos   { return x.ValuePos }
 
-func (x *Ident) End() token.Pos { return token.Pos(len(x.Name) + int(x.NamePos)) }
+func (x *BadExpr) End() token.Pos     { return x.To }
+func (x *OmittedExpr) End() token.Pos { return x.At }
+func (x *Ident) End() token.Pos {
+	if x.Bracketed {
+		return token.Pos(len(x.Name) + 2 + int(x.NamePos))
+	}
+	return token.Pos(len(x.Name) + int(x.NamePos))
+}
 func (x *CallExpr) End() token.Pos {
 	if x.Rparen.IsValid() {
-		return x.Rparen
+		return x.Rparen + 1
 	}
 	if len(x.Recv) > 0 {
 		return x.Recv[len(x.Recv)-1].End()
 	}
 	return x.Func.End()
 }
-func (x *IndexExpr) End() token.Pos     { return x.Rparen }
-func (x *IndexListExpr) End() token.Pos { return x.Rparen }
+func (x *IndexExpr) End() token.Pos     { return x.Rparen + 1 }
+func (x *IndexListExpr) End() token.Pos { return x.Rparen + 1 }
 func (x *NewExpr) End() token.Pos       { return x.X.End() }
+func (x *ParenExpr) End() token.Pos     { return x.Rparen + 1 }
 func (x *SelectorExpr) End() token.Pos  { return x.Sel.End() }
+func (x *UnaryExpr) End() token.Pos     { return x.X.End() }
 func (x *BinaryExpr) End() token.Pos    { return x.Y.End() }
-func (x *BasicLit) End() token.Pos      { return token.Pos(int(x.ValuePos) + len(x.Value)) }
+func (x *BasicLit) End() token.Pos {
+	if x.ValueEnd.IsValid() {
+		return x.ValueEnd
+	}
+	if x.Kind == token.STRING {
+		return token.Pos(int(x.ValuePos) + len(vbsconv.Quote(x.Value)))
+	}
+	return token.Pos(int(x.ValuePos) + len(x.Value))
+}
 
+func (*BadExpr) exprNode()       {}
+func (*OmittedExpr) exprNode()   {}
 func (*Ident) exprNode()         {}
 func (*CallExpr) exprNode()      {}
 func (*IndexExpr) exprNode()     {}
 func (*IndexListExpr) exprNode() {}
 func (*NewExpr) exprNode()       {}
+func (*ParenExpr) exprNode()     {}
 func (*SelectorExpr) exprNode()  {}
+func (*UnaryExpr) exprNode()     {}
 func (*BinaryExpr) exprNode()    {}
 func (*BasicLit) exprNode()      {}
 
 type File struct {
-	Doc *CommentGroup
+	Doc *CommentGroup // associated documentation; or nil
 
 	Stmts []Stmt
+	Seps  []Sep // Seps[i] terminates Stmts[i]; missing entries stand for a newline
 	Decls []Decl
+
+	Comments []*CommentGroup // list of all comments in the source file
 }
 
 func (*File) Pos() token.Pos { return token.NoPos }
@@ -19,7 +19,7 @@ func TestAST(t *testing.T) {
 		{
 			&ast.File{
 				Decls: []ast.Decl{
-					&ast.DimDecl{List: []ast.Expr{&ast.Ident{Name: "A"}}},
+					&ast.DimDecl{Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "A"}}}},
 				},
 				Stmts: []ast.Stmt{
 					&ast.AssignStmt{
@@ -33,9 +33,9 @@ func TestAST(t *testing.T) {
 			}, `Dim A
 A = Array(10,20,30)`},
 		{&ast.File{Decls: []ast.Decl{
-			&ast.DimDecl{List: []ast.Expr{&ast.IndexExpr{X: &ast.Ident{Name: "Names"}, Index: &ast.Ident{Name: "9"}}}},
-			&ast.DimDecl{List: []ast.Expr{&ast.IndexListExpr{X: &ast.Ident{Name: "Names"}, Indices: []ast.Expr{&ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}}}}},
-			&ast.DimDecl{List: []ast.Expr{&ast.Ident{Name: "MyVar"}, &ast.Ident{Name: "MyNum"}}}}}, `Dim Names(9)
+			&ast.DimDecl{Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "Names"}, Bounds: []ast.Expr{&ast.Ident{Name: "9"}}}}},
+			&ast.DimDecl{Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "Names"}, Bounds: []ast.Expr{&ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}}}}},
+			&ast.DimDecl{Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "MyVar"}}, {Name: &ast.Ident{Name: "MyNum"}}}}}}, `Dim Names(9)
 Dim Names(10, 10, 10)
 Dim MyVar, MyNum`},
 		{&ast.BlockStmt{List: []ast.Stmt{
@@ -79,37 +79,36 @@ Err.Clear      ' Clear the error`},
 
 func TestPrint(t *testing.T) {
 	ast.Print(&ast.File{
-		Decls: []ast.Decl{
-			&ast.DimDecl{
-				List:  []ast.Expr{&ast.Ident{Name: "x"}},
-				Colon: token.DynPos,
-				Set: &ast.AssignStmt{
-					Lhs: &ast.Ident{Name: "x"},
-					Rhs: &ast.CallExpr{
-						Func: &ast.Ident{Name: "CreateObject"},
-						Recv: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: "Scripting.Dictionary"}},
-					},
+		Stmts: []ast.Stmt{
+			&ast.DeclStmt{Decl: &ast.DimDecl{Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "x"}}}}},
+			&ast.AssignStmt{
+				Tok: token.SET,
+				Lhs: &ast.Ident{Name: "x"},
+				Rhs: &ast.CallExpr{
+					Func: &ast.Ident{Name: "CreateObject"},
+					Recv: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: "Scripting.Dictionary"}},
 				},
 			},
+		},
+		Seps: []ast.Sep{{Tok: token.COLON}},
+		Decls: []ast.Decl{
 			&ast.ReDimDecl{
 				Preserve: token.DynPos,
-				List: []ast.Expr{
-					&ast.IndexListExpr{
-						X:       &ast.Ident{Name: "X"},
-						Indices: []ast.Expr{&ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}, &ast.Ident{Name: "15"}},
+				Specs: []*ast.VarSpec{
+					{
+						Name:   &ast.Ident{Name: "X"},
+						Bounds: []ast.Expr{&ast.Ident{Name: "10"}, &ast.Ident{Name: "10"}, &ast.Ident{Name: "15"}},
 					},
 				},
 			},
 			&ast.ClassDecl{
 				Mod:  ast.M_PUBLIC,
 				Name: &ast.Ident{Name: "RGB"},
-				Stmts: []ast.Stmt{
-					&ast.MemberStmt{
-						Mod:  ast.M_PRIVATE,
-						Name: &ast.Ident{Name: "m_value"},
-					},
-				},
 				Decls: []ast.Decl{
+					&ast.VarDecl{
+						Mod:   ast.M_PRIVATE,
+						Specs: []*ast.VarSpec{{Name: &ast.Ident{Name: "m_value"}}},
+					},
 					&ast.PropertyDecl{
 						Mod:  ast.M_PUBLIC,
 						Tok:  token.GET,
@@ -209,6 +208,111 @@ func TestPrint(t *testing.T) {
 				},
 			},
 		},
-		Stmts: []ast.Stmt{},
 	})
 }
+
+func TestPrintParams(t *testing.T) {
+	// fields built without positions keep their ByVal and ByRef
+	decl := &ast.FuncDecl{
+		Mod:    ast.M_PUBLIC | ast.M_DEFAULT,
+		Name:   &ast.Ident{Name: "Item"},
+		Lparen: token.DynPos,
+		Recv: []*ast.Field{
+			{Tok: token.BYVAL, Name: &ast.Ident{Name: "key"}},
+			{Tok: token.BYREF, Name: &ast.Ident{Name: "found"}},
+			{Name: &ast.Ident{Name: "list"}, Lparen: token.DynPos, Rparen: token.DynPos},
+		},
+		Body: &ast.BlockStmt{},
+	}
+	assert.Equal(t, "Public Default Function Item(ByVal key, ByRef found, list())\nEnd Function\n", ast.String(decl))
+}
+
+func TestPrintConstDoc(t *testing.T) {
+	decl := &ast.ConstDecl{
+		Doc: &ast.CommentGroup{List: []*ast.Comment{
+			{Tok: token.APOSTROPHE, Text: " Limits"},
+			{Tok: token.REM, Text: " of the box"},
+		}},
+		Mod:   ast.M_PRIVATE,
+		Specs: []*ast.ValueSpec{{Name: &ast.Ident{Name: "Size"}, Value: &ast.BasicLit{Kind: token.INTEGER, Value: "10"}}},
+	}
+	assert.Equal(t, "' Limits\nRem of the box\nPrivate Const Size = 10\n", ast.String(decl))
+}
+
+func TestPrintSeps(t *testing.T) {
+	file := &ast.File{
+		Stmts: []ast.Stmt{
+			&ast.AssignStmt{Lhs: &ast.Ident{Name: "a"}, Rhs: &ast.BasicLit{Kind: token.INTEGER, Value: "1"}},
+			&ast.AssignStmt{Lhs: &ast.Ident{Name: "b"}, Rhs: &ast.BasicLit{Kind: token.INTEGER, Value: "2"}},
+			&ast.WhileWendStmt{
+				Cond: &ast.Ident{Name: "a"},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.AssignStmt{Lhs: &ast.Ident{Name: "a"}, Rhs: &ast.Ident{Name: "b"}},
+						&ast.StopStmt{},
+					},
+					Seps: []ast.Sep{{Tok: token.COLON}},
+				},
+			},
+		},
+		Seps: []ast.Sep{{Tok: token.COLON}, {Tok: token.NEWLINE}},
+	}
+	assert.Equal(t, `a = 1 : b = 2
+While a
+  a = b : Stop
+Wend
+`, ast.String(file))
+}
+
+func TestExprStrLiterals(t *testing.T) {
+	lit := &ast.BasicLit{Kind: token.STRING, Value: `He said "hi"`, ValuePos: 5}
+	assert.Equal(t, `"He said ""hi"""`, ast.ExprStr(lit))
+	assert.Equal(t, token.Pos(5+len(`"He said ""hi"""`)), lit.End())
+	assert.Equal(t, "&HFF", ast.ExprStr(&ast.BasicLit{Kind: token.INTEGER, Value: "&HFF"}))
+	assert.Equal(t, "#10/16/2026#", ast.ExprStr(&ast.BasicLit{Kind: token.DATE, Value: "#10/16/2026#"}))
+
+	// line breaks are concatenated, in parentheses where an operator binds more tightly
+	html := &ast.BasicLit{Kind: token.STRING, Value: "<p>\r\n"}
+	assert.Equal(t, `"<p>" & vbCrLf`, ast.ExprStr(html))
+	assert.Equal(t, `"<p>" & vbCrLf & x`, ast.ExprStr(&ast.BinaryExpr{X: html, Op: token.BITAND, Y: &ast.Ident{Name: "x"}}))
+	assert.Equal(t, `x + ("<p>" & vbCrLf)`, ast.ExprStr(&ast.BinaryExpr{X: &ast.Ident{Name: "x"}, Op: token.ADD, Y: html}))
+	assert.Equal(t, `vbCr & vbCrLf & """x""" & vbLf`, ast.ExprStr(&ast.BasicLit{Kind: token.STRING, Value: "\r\r\n\"x\"\n"}))
+}
+
+func TestBracketedIdent(t *testing.T) {
+	id := &ast.Ident{NamePos: 10, Name: "my odd name", Bracketed: true}
+	assert.Equal(t, "[my odd name]", ast.ExprStr(id))
+	assert.Equal(t, token.Pos(10+len("[my odd name]")), id.End())
+	assert.Equal(t, "größe", ast.ExprStr(&ast.Ident{Name: "größe"}))
+	assert.Equal(t, "[Sub].[End]", ast.ExprStr(&ast.SelectorExpr{
+		X:   &ast.Ident{Name: "Sub", Bracketed: true},
+		Sel: &ast.Ident{Name: "End", Bracketed: true},
+	}))
+}
+
+func TestExprStrPrecedence(t *testing.T) {
+	a, b, c := &ast.Ident{Name: "a"}, &ast.Ident{Name: "b"}, &ast.Ident{Name: "c"}
+	bin := func(x ast.Expr, op token.Token, y ast.Expr) ast.Expr { return &ast.BinaryExpr{X: x, Op: op, Y: y} }
+	un := func(op token.Token, x ast.Expr) ast.Expr { return &ast.UnaryExpr{Op: op, X: x} }
+	for want, x := range map[string]ast.Expr{
+		"(a + b) * c":       bin(bin(a, token.ADD, b), token.MUL, c),
+		"a + b * c":         bin(a, token.ADD, bin(b, token.MUL, c)),
+		"a - b - c":         bin(bin(a, token.SUB, b), token.SUB, c),
+		"a - (b - c)":       bin(a, token.SUB, bin(b, token.SUB, c)),
+		"a & b = c":         bin(bin(a, token.BITAND, b), token.EQ, c),
+		"-a * b":            bin(un(token.SUB, a), token.MUL, b),
+		"(-a) ^ b":          bin(un(token.SUB, a), token.EXP, b),
+		"-a ^ b":            un(token.SUB, bin(a, token.EXP, b)),
+		"-(a + b)":          un(token.SUB, bin(a, token.ADD, b)),
+		"Not a And b":       bin(un(token.NOT, a), token.AND, b),
+		"(Not a) = b":       bin(un(token.NOT, a), token.EQ, b),
+		"Not a = b":         un(token.NOT, bin(a, token.EQ, b)),
+		"a + (Not b) = c":   bin(bin(a, token.ADD, un(token.NOT, b)), token.EQ, c),
+		"a Or Not b":        bin(a, token.OR, un(token.NOT, b)),
+		"(a Or b).c":        &ast.SelectorExpr{X: bin(a, token.OR, b), Sel: c},
+		"(-a)(b)":           &ast.IndexExpr{X: un(token.SUB, a), Index: b},
+		"(a + b) * c + (c)": bin(bin(&ast.ParenExpr{X: bin(a, token.ADD, b)}, token.MUL, c), token.ADD, &ast.ParenExpr{X: c}),
+	} {
+		assert.Equal(t, want, ast.ExprStr(x))
+	}
+}
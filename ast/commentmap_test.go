@@ -0,0 +1,81 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentMap(t *testing.T) {
+	const src = `Rem leading comment
+x = 1 ' trailing
+Sub Foo
+End Sub ' done
+`
+	fset := token.NewFileSet()
+	f := fset.AddFile("test.vbs", -1, len(src))
+	f.SetLinesForContent([]byte(src))
+	pos := func(offset int) token.Pos { return f.Pos(offset) }
+
+	leading := &ast.CommentGroup{List: []*ast.Comment{{TokPos: pos(0), Tok: token.REM, Text: " leading comment"}}}
+	trailing := &ast.CommentGroup{List: []*ast.Comment{{TokPos: pos(26), Tok: token.APOSTROPHE, Text: " trailing"}}}
+	done := &ast.CommentGroup{List: []*ast.Comment{{TokPos: pos(53), Tok: token.APOSTROPHE, Text: " done"}}}
+
+	assign := &ast.AssignStmt{
+		Lhs:    &ast.Ident{NamePos: pos(20), Name: "x"},
+		Assign: pos(22),
+		Rhs:    &ast.BasicLit{ValuePos: pos(24), Kind: token.INTEGER, Value: "1"},
+	}
+	sub := &ast.SubDecl{Sub: pos(37), Name: &ast.Ident{NamePos: pos(41), Name: "Foo"}, Body: &ast.BlockStmt{}, EndSub: pos(45)}
+	file := &ast.File{
+		Stmts:    []ast.Stmt{assign},
+		Decls:    []ast.Decl{sub},
+		Comments: []*ast.CommentGroup{trailing, done, leading},
+	}
+
+	assert.Equal(t, token.Pos(f.Pos(36)), trailing.End())
+	assert.Equal(t, "leading comment", leading.Text())
+
+	cmap := ast.NewCommentMap(fset, file, file.Comments)
+	assert.Equal(t, []*ast.CommentGroup{leading, trailing}, cmap[assign])
+	assert.Equal(t, []*ast.CommentGroup{done}, cmap[sub])
+	assert.Equal(t, []*ast.CommentGroup{leading, trailing, done}, cmap.Comments())
+
+	// keep the comments of a rewritten statement
+	call := &ast.ExprStmt{X: &ast.CallExpr{Func: &ast.Ident{Name: "Init"}}}
+	file.Stmts[0] = cmap.Update(assign, call).(ast.Stmt)
+	assert.Equal(t, []*ast.CommentGroup{leading, trailing}, cmap[call])
+	assert.NotContains(t, cmap, assign)
+
+	file.Decls = nil
+	assert.Equal(t, ast.CommentMap{call: {leading, trailing}}, cmap.Filter(file))
+}
+
+func TestInspect(t *testing.T) {
+	file := &ast.File{
+		Stmts: []ast.Stmt{
+			&ast.IfStmt{
+				Cond: &ast.BinaryExpr{X: &ast.Ident{Name: "a"}, Op: token.EQ, Y: &ast.BasicLit{Kind: token.INTEGER, Value: "1"}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ExprStmt{X: &ast.CallExpr{Func: &ast.SelectorExpr{X: &ast.Ident{Name: "WScript"}, Sel: &ast.Ident{Name: "Echo"}}, Recv: []ast.Expr{&ast.Ident{Name: "a"}}}},
+				}},
+			},
+		},
+		Decls: []ast.Decl{
+			&ast.FuncDecl{Name: &ast.Ident{Name: "F"}, Recv: []*ast.Field{{Name: &ast.Ident{Name: "v"}}}, Body: &ast.BlockStmt{}},
+		},
+	}
+	var names []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"a", "WScript", "Echo", "a", "F", "v"}, names)
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/hulo-io/vbsparser/token"
+	"github.com/hulo-io/vbsparser/vbsconv"
 )
 
 var _ Visitor = (*printer)(nil)
@@ -38,23 +39,29 @@ func (p *printer) Visit(node Node) Visitor {
 			Walk(p, d)
 		}
 
-		for _, s := range n.Stmts {
-			Walk(p, s)
-		}
+		p.stmtList(n.Stmts, n.Seps, "")
 
 	case *DimDecl:
-		p.printf("%s %s", p.ident+"Dim", ExprListStr(n.List))
-		if n.Colon.IsValid() {
-			p.printf(": Set %s = %s", ExprStr(n.Set.Lhs), ExprStr(n.Set.Rhs))
-		}
-		p.println()
+		p.println(p.ident + "Dim " + varSpecsStr(n.Specs))
 
 	case *ReDimDecl:
-		p.print("ReDim ")
+		p.print(p.ident + "ReDim ")
 		if n.Preserve.IsValid() {
 			p.print("Preserve ")
 		}
-		p.println(ExprListStr(n.List))
+		p.println(varSpecsStr(n.Specs))
+
+	case *VarDecl:
+		p.println(p.ident + modStr(n.Mod) + varSpecsStr(n.Specs))
+
+	case *ConstDecl:
+		p.commentGroup(n.Doc)
+		p.print(p.ident + modStr(n.Mod))
+		specs := make([]string, len(n.Specs))
+		for i, s := range n.Specs {
+			specs[i] = ExprStr(s.Name) + " = " + ExprStr(s.Value)
+		}
+		p.println("Const " + strings.Join(specs, ", "))
 
 	case *ClassDecl:
 		if n.Mod.HasPublic() {
@@ -67,111 +74,55 @@ func (p *printer) Visit(node Node) Visitor {
 		for _, d := range n.Decls {
 			Walk(p, d)
 		}
-
-		for _, s := range n.Stmts {
-			Walk(p, s)
-		}
 		p.ident = temp
 
 		p.println(p.ident + "End Class")
 
 	case *SubDecl:
-		ident := p.ident
-		if n.Mod.HasPublic() {
-			p.print(p.ident + "Public ")
-			ident = ""
-		}
-		p.printf(ident+"Sub %s\n", ExprStr(n.Name))
+		p.printf(p.ident+"%sSub %s%s\n", modStr(n.Mod), ExprStr(n.Name), paramsStr(n.Lparen, n.Recv))
 
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 
 		p.println(p.ident + "End Sub")
 
 	case *FuncDecl:
-		ident := p.ident
-		if n.Mod.HasPublic() {
-			p.print(ident + "Public ")
-			ident = ""
-		}
-		list := []string{}
-		for _, r := range n.Recv {
-			if r.TokPos.IsValid() {
-				list = append(list, fmt.Sprintf("%s %s", r.Tok, r.Name.Name))
-			} else {
-				list = append(list, r.Name.Name)
-			}
-		}
-		p.printf(ident+"Function %s(%s)\n", ExprStr(n.Name), strings.Join(list, ", "))
+		p.printf(p.ident+"%sFunction %s%s\n", modStr(n.Mod), ExprStr(n.Name), paramsStr(n.Lparen, n.Recv))
 
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 
 		p.println(p.ident + "End Function")
 
 	case *PropertyDecl:
-		ident := p.ident
-		if n.Mod.HasPublic() {
-			p.print(ident + "Public ")
-			ident = ""
-		}
-		list := []string{}
-		for _, r := range n.Recv {
-			if r.TokPos.IsValid() {
-				list = append(list, fmt.Sprintf("%s %s", r.Tok, r.Name.Name))
-			} else {
-				list = append(list, r.Name.Name)
-			}
-		}
-		p.printf(ident+"Property %s %s(%s)\n", n.Tok, ExprStr(n.Name), strings.Join(list, ", "))
+		p.printf(p.ident+"%sProperty %s %s%s\n", modStr(n.Mod), n.Tok, ExprStr(n.Name), paramsStr(n.Lparen, n.Recv))
 
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 
 		p.println(p.ident + "End Property")
 
 	case *IfStmt:
+		if n.SingleLine {
+			p.printf(p.ident+"If %s Then %s", ExprStr(n.Cond), lineStmtList(n.Body))
+			if n.Else != nil {
+				p.printf(" Else %s", lineStmtList(n.Else))
+			}
+			p.println()
+			break
+		}
+
 		p.printf(p.ident+"If %s Then\n", ExprStr(n.Cond))
 
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 
 		if n.ElseIf != nil {
 			for _, elif := range n.ElseIf {
 				p.println(p.ident+"ElseIf", ExprStr(elif.Cond), "Then")
-				for _, s := range elif.Body.List {
-					temp := p.ident
-					p.ident += "  "
-					Walk(p, s)
-					p.ident = temp
-				}
+				p.block(elif.Body, "  ")
 			}
 		}
 
 		if n.Else != nil {
 			p.println(p.ident + "Else")
-			for _, s := range n.Else.List {
-				temp := p.ident
-				p.ident += "  "
-				Walk(p, s)
-				p.ident = temp
-			}
+			p.block(n.Else, "  ")
 		}
 
 		p.println(p.ident + "End If")
@@ -179,34 +130,22 @@ func (p *printer) Visit(node Node) Visitor {
 	case *ExprStmt:
 		p.printf(p.ident+"%s\n", ExprStr(n.X))
 
-	case *MemberStmt:
-		if n.Mod.HasPublic() {
-			p.print(p.ident + "Public ")
-		}
-		if n.Mod.HasPrivate() {
-			p.print(p.ident + "Private ")
-		}
-		p.println(ExprStr(n.Name))
+	case *DeclStmt:
+		Walk(p, n.Decl)
 
 	case *AssignStmt:
 		modifier := ""
 		switch n.Tok {
 		case token.SET:
 			modifier = "Set "
-		case token.CONST:
-			modifier = "Const "
+		case token.LET:
+			modifier = "Let "
 		}
 		p.printf(p.ident+"%s%s = %s\n", modifier, ExprStr(n.Lhs), ExprStr(n.Rhs))
 
 	case *ForEachStmt:
-		p.printf(p.ident+"For Each %s In %s\n", ExprStr(n.Elem), ExprStr(n.Group))
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
-		p.print(p.ident + "Next")
+		p.loop(fmt.Sprintf("For Each %s In %s", ExprStr(n.Elem), ExprStr(n.Group)), n.Sep, n.Body)
+		p.print("Next")
 		if n.Stmt != nil {
 			p.print(" ")
 			Walk(p, n)
@@ -215,54 +154,40 @@ func (p *printer) Visit(node Node) Visitor {
 		}
 
 	case *ForNextStmt:
-		p.printf(p.ident+"For %s To %s", ExprStr(n.Start), ExprStr(n.End_))
+		head := fmt.Sprintf("For %s To %s", ExprStr(n.Start), ExprStr(n.End_))
 		if n.Step != nil {
-			p.printf(" Step %s\n", ExprStr(n.Step))
+			head += fmt.Sprintf(" Step %s", ExprStr(n.Step))
 		}
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
-		p.println(p.ident + "Next")
+		p.loop(head, n.Sep, n.Body)
+		p.println("Next")
 
 	case *WhileWendStmt:
 		p.printf(p.ident+"While %s\n", ExprStr(n.Cond))
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 		p.println(p.ident + "Wend")
 
 	case *DoLoopStmt:
-		if n.Pre {
-			p.printf(p.ident+"Do %s %s\n", n.Tok, ExprStr(n.Cond))
-			for _, s := range n.Body.List {
-				temp := p.ident
-				p.ident += "  "
-				Walk(p, s)
-				p.ident = temp
-			}
-			p.println(p.ident + "Loop")
-		} else {
-			p.printf(p.ident + "Do\n")
-			for _, s := range n.Body.List {
-				temp := p.ident
-				p.ident += "  "
-				Walk(p, s)
-				p.ident = temp
-			}
-			p.printf(p.ident+"Loop %s %s\n", n.Tok, ExprStr(n.Cond))
+		switch {
+		case n.Cond == nil:
+			p.loop("Do", n.Sep, n.Body)
+			p.println("Loop")
+		case n.Pre:
+			p.loop(fmt.Sprintf("Do %s %s", n.Tok, ExprStr(n.Cond)), n.Sep, n.Body)
+			p.println("Loop")
+		default:
+			p.loop("Do", n.Sep, n.Body)
+			p.printf("Loop %s %s\n", n.Tok, ExprStr(n.Cond))
 		}
 
 	case *CallStmt:
-		p.printf(p.ident+"Call %s %s\n", ExprStr(n.Name), ExprListStr(n.Recv))
+		if n.Recv != nil {
+			p.printf(p.ident+"Call %s(%s)\n", ExprStr(n.Name), ExprListStr(n.Recv))
+		} else {
+			p.printf(p.ident+"Call %s\n", ExprStr(n.Name))
+		}
 
 	case *ExitStmt:
-		if len(n.X) == 0 {
+		if n.X == token.ILLEGAL {
 			p.println(p.ident + "Exit")
 		} else {
 			p.printf(p.ident+"Exit %s\n", n.X)
@@ -271,33 +196,18 @@ func (p *printer) Visit(node Node) Visitor {
 	case *SelectStmt:
 		p.printf(p.ident+"Select Case %s\n", ExprStr(n.Var))
 		for _, c := range n.Cases {
-			p.printf(p.ident+"  Case %s\n", ExprStr(c.Cond))
-			for _, s := range c.Body.List {
-				temp := p.ident
-				p.ident += "    "
-				Walk(p, s)
-				p.ident = temp
-			}
+			p.printf(p.ident+"  Case %s\n", ExprListStr(c.List))
+			p.block(c.Body, "    ")
 		}
 		if n.Else != nil {
 			p.println(p.ident + "  Case Else")
-			for _, s := range n.Else.Body.List {
-				temp := p.ident
-				p.ident += "    "
-				Walk(p, s)
-				p.ident = temp
-			}
+			p.block(n.Else.Body, "    ")
 		}
 		p.println(p.ident + "End Select")
 
 	case *WithStmt:
 		p.printf(p.ident+"With %s\n", ExprStr(n.Cond))
-		for _, s := range n.Body.List {
-			temp := p.ident
-			p.ident += "  "
-			Walk(p, s)
-			p.ident = temp
-		}
+		p.block(n.Body, "  ")
 		p.println(p.ident + "End With")
 
 	case *OnErrorStmt:
@@ -309,14 +219,107 @@ func (p *printer) Visit(node Node) Visitor {
 		}
 	case *StopStmt:
 		p.println(p.ident + "Stop")
+	case *EraseStmt:
+		p.printf(p.ident+"Erase %s\n", ExprListStr(n.List))
+	case *ExecuteStmt:
+		if n.Global {
+			p.printf(p.ident+"ExecuteGlobal %s\n", ExprStr(n.X))
+		} else {
+			p.printf(p.ident+"Execute %s\n", ExprStr(n.X))
+		}
 	case *RandomizeStmt:
-		p.println(p.ident + "Randomize")
+		if n.Seed != nil {
+			p.printf(p.ident+"Randomize %s\n", ExprStr(n.Seed))
+		} else {
+			p.println(p.ident + "Randomize")
+		}
 	case *OptionStmt:
 		p.println(p.ident + "Option Explicit")
 	}
 	return nil
 }
 
+// commentGroup prints the comments of g, one per line; g may be nil.
+func (p *printer) commentGroup(g *CommentGroup) {
+	if g == nil {
+		return
+	}
+	for _, c := range g.List {
+		p.println(p.ident + c.Tok.String() + c.Text)
+	}
+}
+
+// block prints the statements of b indented by indent relative to
+// the current indentation.
+func (p *printer) block(b *BlockStmt, indent string) {
+	p.stmtList(b.List, b.Seps, indent)
+}
+
+// stmtList prints list indented by indent relative to the current
+// indentation. A statement terminated by a colon is followed by the
+// next statement on the same line.
+func (p *printer) stmtList(list []Stmt, seps []Sep, indent string) {
+	ident := p.ident + indent
+	joined := false
+	for i, s := range list {
+		buf := &strings.Builder{}
+		Walk(&printer{ident: ident, output: buf}, s)
+		out := buf.String()
+		if joined {
+			out = strings.TrimPrefix(out, ident)
+		}
+		joined = i+1 < len(list) && i < len(seps) && seps[i].Tok == token.COLON
+		if joined {
+			out = strings.TrimSuffix(out, "\n") + " : "
+		}
+		p.print(out)
+	}
+}
+
+// loop prints the header head of a loop terminated by sep and the body
+// indented, up to the closing keyword, which the caller prints. A colon
+// after the header or after the last statement of the body keeps the
+// following statement or keyword on the same line.
+func (p *printer) loop(head string, sep Sep, body *BlockStmt) {
+	p.print(p.ident + head)
+	joined := sep.Tok == token.COLON
+	if joined {
+		p.print(" : ")
+	} else {
+		p.println()
+	}
+	if n := len(body.List); n > 0 {
+		buf := &strings.Builder{}
+		(&printer{ident: p.ident, output: buf}).block(body, "  ")
+		out := buf.String()
+		if joined {
+			out = strings.TrimPrefix(out, p.ident+"  ")
+		}
+		joined = len(body.Seps) == n && body.Seps[n-1].Tok == token.COLON
+		if joined {
+			out = strings.TrimSuffix(out, "\n") + " : "
+		}
+		p.print(out)
+	}
+	if !joined {
+		p.print(p.ident)
+	}
+}
+
+// lineStmtList returns the statements of b on a single line, separated
+// by colons, as in the single-line form of an If statement.
+func lineStmtList(b *BlockStmt) string {
+	list := make([]string, len(b.List))
+	for i, s := range b.List {
+		list[i] = strings.TrimSuffix(String(s), "\n")
+	}
+	out := strings.Join(list, " : ")
+	if len(b.Seps) > 0 && len(b.Seps) == len(b.List) {
+		out += " :" // trailing colon
+	}
+	return out
+}
+
 func Print(node Node) {
 	Walk(&printer{ident: "", output: os.Stdout}, node)
 }
@@ -327,33 +330,172 @@ func String(node Node) string {
 	return buf.String()
 }
 
+// ExprStr returns the source form of e. Parentheses are inserted where
+// the structure of the tree differs from the operator precedence, so
+// that a tree built without ParenExpr nodes still reads back the same.
 func ExprStr(e Expr) string {
+	return exprStr(e, token.LowestPrec+1, token.LowestPrec)
+}
+
+// postfixPrec is the binding of selectors, calls and indices, which is
+// tighter than that of any operator.
+const postfixPrec = token.HighestPrec + 1
+
+// exprStr returns the source form of e as an operand that binds at least
+// as tightly as prec1, followed by an operator of precedence next, or by
+// no operator if next is LowestPrec. A unary operator extends as far to
+// the right as the operators binding more tightly than itself, so it
+// needs parentheses if such an operator follows.
+func exprStr(e Expr, prec1, next int) string {
 	switch e := e.(type) {
+	case *OmittedExpr:
+		return ""
 	case *Ident:
+		if e.Bracketed {
+			return "[" + e.Name + "]"
+		}
 		return e.Name
 	case *BasicLit:
 		if e.Kind == token.STRING {
-			return fmt.Sprintf(`"%s"`, e.Value)
+			// a string literal cannot span lines, so line breaks are
+			// concatenated as vbCrLf, vbCr and vbLf
+			s := stringStr(e.Value)
+			if prec := token.BITAND.Precedence(); s != vbsconv.Quote(e.Value) && (prec < prec1 || next > prec) {
+				return "(" + s + ")"
+			}
+			return s
 		}
 		return e.Value
 	case *SelectorExpr:
-		return fmt.Sprintf("%s.%s", ExprStr(e.X), ExprStr(e.Sel))
+		if e.X == nil {
+			return "." + ExprStr(e.Sel)
+		}
+		return fmt.Sprintf("%s.%s", operandStr(e.X), ExprStr(e.Sel))
+	case *UnaryExpr:
+		prec, op := token.UnaryPrec, e.Op.String()
+		if e.Op == token.NOT {
+			prec, op = token.NotPrec, "Not "
+		}
+		if next > prec {
+			return "(" + op + exprStr(e.X, prec+1, token.LowestPrec) + ")"
+		}
+		return op + exprStr(e.X, prec+1, next)
+	case *NewExpr:
+		return "New " + ExprStr(e.X)
 	case *BinaryExpr:
-		return fmt.Sprintf("%s %s %s", ExprStr(e.X), e.Op, ExprStr(e.Y))
+		prec := e.Op.Precedence()
+		if prec < prec1 {
+			return "(" + exprStr(e, token.LowestPrec+1, token.LowestPrec) + ")"
+		}
+		// all binary operators are left associative
+		return fmt.Sprintf("%s %s %s", exprStr(e.X, prec, prec), e.Op, exprStr(e.Y, prec+1, next))
+	case *ParenExpr:
+		return "(" + ExprStr(e.X) + ")"
 	case *CallExpr:
-		return fmt.Sprintf("%s(%s)", ExprStr(e.Func), ExprListStr(e.Recv))
+		if e.Stmt {
+			if len(e.Recv) == 0 {
+				return ExprStr(e.Func)
+			}
+			return ExprStr(e.Func) + " " + ExprListStr(e.Recv)
+		}
+		return fmt.Sprintf("%s(%s)", operandStr(e.Func), ExprListStr(e.Recv))
 	case *IndexExpr:
-		return fmt.Sprintf("%s(%s)", ExprStr(e.X), ExprStr(e.Index))
+		return fmt.Sprintf("%s(%s)", operandStr(e.X), ExprStr(e.Index))
 	case *IndexListExpr:
-		return fmt.Sprintf("%s(%s)", ExprStr(e.X), ExprListStr(e.Indices))
+		return fmt.Sprintf("%s(%s)", operandStr(e.X), ExprListStr(e.Indices))
 	}
 	return ""
 }
 
+// stringStr returns the source form of the string s: a string literal,
+// or a concatenation such as "a" & vbCrLf & "b" if s contains line breaks.
+func stringStr(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return vbsconv.Quote(s)
+	}
+	var parts []string
+	for s != "" {
+		i := strings.IndexAny(s, "\r\n")
+		if i < 0 {
+			parts = append(parts, vbsconv.Quote(s))
+			break
+		}
+		if i > 0 {
+			parts = append(parts, vbsconv.Quote(s[:i]))
+		}
+		switch {
+		case strings.HasPrefix(s[i:], "\r\n"):
+			parts = append(parts, "vbCrLf")
+			i++
+		case s[i] == '\r':
+			parts = append(parts, "vbCr")
+		default:
+			parts = append(parts, "vbLf")
+		}
+		s = s[i+1:]
+	}
+	return strings.Join(parts, " & ")
+}
+
+// operandStr returns the source form of the operand of a selector,
+// call or index expression.
+func operandStr(x Expr) string {
+	return exprStr(x, postfixPrec, postfixPrec)
+}
+
 func ExprListStr(list []Expr) string {
 	res := []string{}
 	for _, e := range list {
 		res = append(res, ExprStr(e))
 	}
+	// an omitted last argument leaves just the comma, as in f(a,)
+	return strings.TrimSuffix(strings.Join(res, ", "), " ")
+}
+
+// varSpecsStr returns the string form of a list of variables, such as
+// "a, b(9, 3), c()".
+func varSpecsStr(specs []*VarSpec) string {
+	res := make([]string, len(specs))
+	for i, s := range specs {
+		res[i] = ExprStr(s.Name)
+		if s.Dynamic || len(s.Bounds) > 0 {
+			res[i] += "(" + ExprListStr(s.Bounds) + ")"
+		}
+	}
 	return strings.Join(res, ", ")
 }
+
+// modStr returns the modifiers m followed by a blank, such as
+// "Public Default ", or "" if there are none.
+func modStr(m Modifier) string {
+	var s string
+	if m.HasPublic() {
+		s += "Public "
+	}
+	if m.HasPrivate() {
+		s += "Private "
+	}
+	if m.HasDefault() {
+		s += "Default "
+	}
+	return s
+}
+
+// paramsStr returns the parameter list of a procedure, such as
+// "(ByVal a, b())", or "" for a procedure declared without one.
+func paramsStr(lparen token.Pos, list []*Field) string {
+	if !lparen.IsValid() && len(list) == 0 {
+		return ""
+	}
+	res := make([]string, len(list))
+	for i, f := range list {
+		if f.Tok == token.BYVAL || f.Tok == token.BYREF {
+			res[i] = f.Tok.String() + " "
+		}
+		res[i] += ExprStr(f.Name)
+		if f.IsArray() {
+			res[i] += "()"
+		}
+	}
+	return "(" + strings.Join(res, ", ") + ")"
+}
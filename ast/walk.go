@@ -10,6 +10,11 @@ type Visitor interface {
 	Visit(node Node) (w Visitor)
 }
 
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
 func Walk(v Visitor, node Node) {
 	if v = v.Visit(node); v == nil {
 		return
@@ -22,78 +27,250 @@ func Walk(v Visitor, node Node) {
 	// Comments and fields
 	case *Comment:
 		// nothing to do
+
 	case *CommentGroup:
 		for _, c := range n.List {
 			Walk(v, c)
 		}
-	case *Ident:
+
+	// Declarations
+	case *BadDecl:
 		// nothing to do
+
+	case *SubDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.Name)
+		walkFieldList(v, n.Recv)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *FuncDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.Name)
+		walkFieldList(v, n.Recv)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *PropertyDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.Name)
+		walkFieldList(v, n.Recv)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ClassDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.Name)
+		for _, d := range n.Decls {
+			Walk(v, d)
+		}
+
 	case *DimDecl:
-		Walk(v, n)
+		walkVarSpecs(v, n.Specs)
 
 	case *ReDimDecl:
-		Walk(v, n)
+		walkVarSpecs(v, n.Specs)
 
-	case *ClassDecl:
-		Walk(v, n)
+	case *VarDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		walkVarSpecs(v, n.Specs)
 
-	case *FuncDecl:
-		Walk(v, n)
+	case *ConstDecl:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		for _, s := range n.Specs {
+			Walk(v, s)
+		}
 
-	case *PropertyDecl:
-		Walk(v, n)
+	case *Field:
+		Walk(v, n.Name)
 
-	case *SubDecl:
-		Walk(v, n)
+	case *VarSpec:
+		Walk(v, n.Name)
+		walkExprList(v, n.Bounds)
+
+	case *ValueSpec:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+
+	// Statements
+	case *BadStmt, *OptionStmt, *StopStmt, *ExitStmt, *OnErrorStmt:
+		// nothing to do
+
+	case *EraseStmt:
+		walkExprList(v, n.List)
+
+	case *ExecuteStmt:
+		Walk(v, n.X)
+
+	case *RandomizeStmt:
+		if n.Seed != nil {
+			Walk(v, n.Seed)
+		}
+
+	case *WithStmt:
+		Walk(v, n.Cond)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
 
 	case *AssignStmt:
-		Walk(v, n)
+		Walk(v, n.Lhs)
+		Walk(v, n.Rhs)
 
-	case *ExprStmt:
-		Walk(v, n)
+	case *SelectStmt:
+		Walk(v, n.Var)
+		for _, c := range n.Cases {
+			Walk(v, c)
+		}
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
 
-	case *MemberStmt:
-		Walk(v, n)
+	case *CaseStmt:
+		walkExprList(v, n.List)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *CaseElseStmt:
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
 
 	case *IfStmt:
-		Walk(v, n)
+		Walk(v, n.Cond)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		for _, s := range n.ElseIf {
+			Walk(v, s)
+		}
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
 
-	case *ForEachStmt:
-		Walk(v, n)
+	case *BlockStmt:
+		for _, s := range n.List {
+			Walk(v, s)
+		}
+
+	case *CallStmt:
+		Walk(v, n.Name)
+		walkExprList(v, n.Recv)
 
 	case *ForNextStmt:
-		Walk(v, n)
+		Walk(v, n.Start)
+		Walk(v, n.End_)
+		if n.Step != nil {
+			Walk(v, n.Step)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ForEachStmt:
+		Walk(v, n.Elem)
+		Walk(v, n.Group)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		if n.Stmt != nil {
+			Walk(v, n.Stmt)
+		}
 
 	case *WhileWendStmt:
-		Walk(v, n)
+		Walk(v, n.Cond)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
 
 	case *DoLoopStmt:
-		Walk(v, n)
+		if n.Pre && n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		if !n.Pre && n.Cond != nil {
+			Walk(v, n.Cond)
+		}
 
-	case *CallStmt:
-		Walk(v, n)
+	case *ExprStmt:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		Walk(v, n.X)
 
-	case *ExitStmt:
-		Walk(v, n)
+	case *DeclStmt:
+		Walk(v, n.Decl)
 
-	case *SelectStmt:
-		Walk(v, n)
+	// Expressions
+	case *BadExpr, *OmittedExpr, *BasicLit, *Ident:
+		// nothing to do
 
-	case *WithStmt:
-		Walk(v, n)
+	case *IndexExpr:
+		Walk(v, n.X)
+		Walk(v, n.Index)
 
-	case *OnErrorStmt:
-		Walk(v, n)
+	case *IndexListExpr:
+		Walk(v, n.X)
+		walkExprList(v, n.Indices)
 
-	case *StopStmt:
-		Walk(v, n)
+	case *NewExpr:
+		Walk(v, n.X)
 
-	case *RandomizeStmt:
-		Walk(v, n)
+	case *ParenExpr:
+		Walk(v, n.X)
+
+	case *CallExpr:
+		Walk(v, n.Func)
+		walkExprList(v, n.Recv)
+
+	case *SelectorExpr:
+		if n.X != nil {
+			Walk(v, n.X)
+		}
+		Walk(v, n.Sel)
+
+	case *UnaryExpr:
+		Walk(v, n.X)
+
+	case *BinaryExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
 
-	case *OptionStmt:
-		Walk(v, n)
+	// Files
+	case *File:
+		if n.Doc != nil {
+			Walk(v, n.Doc)
+		}
+		for _, s := range n.Stmts {
+			Walk(v, s)
+		}
+		for _, d := range n.Decls {
+			Walk(v, d)
+		}
+		// don't walk n.Comments - they have been
+		// visited already through the individual
+		// nodes
 	}
+
+	v.Visit(nil)
 }
 
 func walkExprList(v Visitor, list []Expr) {
@@ -101,3 +278,32 @@ func walkExprList(v Visitor, list []Expr) {
 		Walk(v, x)
 	}
 }
+
+func walkVarSpecs(v Visitor, list []*VarSpec) {
+	for _, s := range list {
+		Walk(v, s)
+	}
+}
+
+func walkFieldList(v Visitor, list []*Field) {
+	for _, f := range list {
+		Walk(v, f)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,260 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package hta extracts and parses the VBScript code of HTML documents
+// and HTML Applications (.hta): <script> blocks and inline event
+// handlers such as onclick="vbscript:Refresh".
+//
+// A handler is VBScript if its value starts with "vbscript:", if its
+// element has a language="VBScript" attribute, or, as in Internet
+// Explorer, if the first script block of the document is VBScript.
+package hta
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/internal/markup"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// A Document holds the VBScript code of an HTML document, in source order.
+type Document struct {
+	Scripts  []*Script
+	Handlers []*Handler
+}
+
+// A Script represents a VBScript <script> element.
+type Script struct {
+	Pos   token.Pos // position of "<script"
+	Src   string    // value of the src attribute; or ""
+	For   string    // value of the for attribute; or ""
+	Event string    // value of the event attribute; or ""
+	File  *ast.File // parsed inline code; or nil if there is none
+}
+
+// A Handler represents an event handler attribute with VBScript code.
+type Handler struct {
+	Pos     token.Pos // position of the attribute
+	Element string    // lower-case element name, e.g. "button"
+	Event   string    // lower-case attribute name, e.g. "onclick"
+	File    *ast.File // parsed code, without the "vbscript:" prefix
+}
+
+// ParseFile parses the VBScript code of an HTML document or HTML
+// Application. The arguments have the same meaning as for
+// parser.ParseFile; mode is passed on for each piece of code.
+//
+// Each script block and handler is parsed separately into an ast.File
+// with a token.File of its own in fset; positions refer to the document.
+// The HTML comment delimiters that traditionally hide a script block
+// from old browsers are ignored, and character references in handlers
+// are replaced; a column counts a character reference as one character.
+//
+// If the source couldn't be read, the returned document is nil and the
+// error indicates the specific failure. If the source was read but errors
+// were found, the result is a partial document and the errors are
+// returned via a scanner.ErrorList which is sorted by source position.
+func ParseFile(fset *token.FileSet, filename string, src any, mode parser.Mode) (doc *Document, err error) {
+	return ParseFileConfig(fset, filename, src, &parser.Config{Mode: mode})
+}
+
+// ParseFileConfig is like ParseFile, with the mode taken from conf.Mode;
+// files in an ANSI code page are decoded with conf.Charmap.
+func ParseFileConfig(fset *token.FileSet, filename string, src any, conf *parser.Config) (doc *Document, err error) {
+	if fset == nil {
+		panic("hta.ParseFile: no token.FileSet provided (fset == nil)")
+	}
+
+	text, err := markup.ReadSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var e extractor
+	e.init(fset, filename, text, conf)
+	doc = e.extract()
+
+	e.errors.Sort()
+	return doc, e.errors.Err()
+}
+
+// A region is a piece of VBScript code in the document.
+type region struct {
+	start, end int
+	cm         markup.Mode
+	file       **ast.File // where to store the result
+}
+
+// An extractor holds the state of extracting the code of a document.
+type extractor struct {
+	fset    *token.FileSet
+	file    *token.File
+	mode    parser.Mode
+	scanner markup.Scanner
+	errors  scanner.ErrorList
+}
+
+func (e *extractor) init(fset *token.FileSet, filename string, src []byte, conf *parser.Config) {
+	text, origin := scanner.Decode(src, conf.Charmap)
+	e.fset = fset
+	e.file = fset.AddFile(filename, -1, len(text))
+	e.file.SetLinesForContent(text)
+	e.file.SetOffsetMap(origin)
+	e.mode = conf.Mode
+	e.scanner = markup.Scanner{Src: text, Unescape: true, Error: e.error}
+}
+
+func (e *extractor) error(offs int, msg string) {
+	e.errors.Add(e.file.Position(e.file.Pos(offs)), msg)
+}
+
+func isVBScript(lang string) bool {
+	lang = strings.ToLower(lang)
+	return lang == "vbscript" || lang == "vbs" || lang == "text/vbscript"
+}
+
+func (e *extractor) extract() *Document {
+	doc := &Document{}
+	var (
+		regions    []region
+		defaults   []region // handlers in the default language
+		firstLang  string
+		seenScript bool
+	)
+	for {
+		it := e.scanner.Next()
+		if it.Kind == markup.EOF {
+			break
+		}
+		if it.Kind != markup.StartTag {
+			continue
+		}
+
+		for _, a := range it.Attrs {
+			if !strings.HasPrefix(a.Name, "on") {
+				continue
+			}
+			start, end := a.ValueOffs, a.ValueEnd
+			for start < end && isSpace(e.scanner.Src[start]) {
+				start++
+			}
+			const prefix = "vbscript:"
+			explicit := end-start >= len(prefix) && strings.EqualFold(string(e.scanner.Src[start:start+len(prefix)]), prefix)
+			if explicit {
+				start += len(prefix)
+			} else if i := strings.IndexByte(a.Value, ':'); i > 0 && isLanguage(strings.TrimSpace(a.Value[:i])) {
+				continue // e.g. "javascript:"
+			}
+			h := &Handler{Pos: e.file.Pos(a.Offs), Element: it.Name, Event: a.Name}
+			doc.Handlers = append(doc.Handlers, h)
+			r := region{start, end, markup.Unescape, &h.File}
+			switch {
+			case explicit, isVBScript(it.Attr("language")):
+				regions = append(regions, r)
+			case it.Attr("language") == "":
+				defaults = append(defaults, r)
+			}
+		}
+
+		switch it.Name {
+		case "script":
+			lang := it.Attr("language")
+			if lang == "" {
+				lang = it.Attr("type")
+			}
+			if !seenScript {
+				firstLang, seenScript = lang, true
+			}
+			if it.Empty {
+				if isVBScript(lang) {
+					doc.Scripts = append(doc.Scripts, &Script{Pos: e.file.Pos(it.Offs), Src: it.Attr("src")})
+				}
+				continue
+			}
+			start, end := e.scanner.RawText(it.Offs, "script")
+			if !isVBScript(lang) {
+				continue
+			}
+			s := &Script{
+				Pos:   e.file.Pos(it.Offs),
+				Src:   it.Attr("src"),
+				For:   it.Attr("for"),
+				Event: it.Attr("event"),
+			}
+			doc.Scripts = append(doc.Scripts, s)
+			start, end = trimComment(e.scanner.Src, start, end)
+			if len(bytes.TrimSpace(e.scanner.Src[start:end])) > 0 {
+				regions = append(regions, region{start, end, 0, &s.File})
+			}
+		case "style", "textarea", "title", "xmp":
+			if !it.Empty {
+				e.scanner.RawText(it.Offs, it.Name)
+			}
+		}
+	}
+
+	if isVBScript(firstLang) {
+		regions = append(regions, defaults...)
+		sort.Slice(regions, func(i, j int) bool { return regions[i].start < regions[j].start })
+	}
+
+	// drop the handlers in another language
+	handlers := doc.Handlers[:0]
+	for _, h := range doc.Handlers {
+		for _, r := range regions {
+			if r.file == &h.File {
+				handlers = append(handlers, h)
+				break
+			}
+		}
+	}
+	doc.Handlers = handlers
+
+	for _, r := range regions {
+		f, errs := markup.ParseCode(e.fset, e.file, e.scanner.Src, r.start, r.end, r.cm, e.mode)
+		*r.file = f
+		e.errors = append(e.errors, errs...)
+	}
+	return doc
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// isLanguage reports whether s names a script language in a handler
+// prefix such as "javascript:".
+func isLanguage(s string) bool {
+	switch strings.ToLower(s) {
+	case "javascript", "jscript", "vbscript", "vbs":
+		return true
+	}
+	return false
+}
+
+// trimComment returns the extent of the script code in src[start:end]
+// without the leading "<!--" and a trailing "-->" that hide it from
+// browsers without scripting.
+func trimComment(src []byte, start, end int) (int, int) {
+	s := start
+	for s < end && isSpace(src[s]) {
+		s++
+	}
+	if bytes.HasPrefix(src[s:end], []byte("<!--")) {
+		start = s + len("<!--")
+	}
+	t := end
+	for t > start && isSpace(src[t-1]) {
+		t--
+	}
+	if bytes.HasSuffix(src[start:t], []byte("-->")) {
+		end = t - len("-->")
+	}
+	return start, end
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package hta_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/hta"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFile(t *testing.T) {
+	const src = `<html>
+<head>
+<hta:application id="admin" applicationname="Admin"/>
+<title>Users & <Groups></title>
+<style>p > b { color: red }</style>
+<script language="VBScript">
+<!--
+Sub Refresh()
+  list.innerHTML = GetUsers()
+End Sub
+'-->
+</script>
+<script type="text/javascript">if (a < b) {}</script>
+<script language="VBScript" for="btn" event="onmouseover">
+  btn.style.color = "red"
+</script>
+</head>
+<body onload="Refresh">
+<button id="btn" onclick="vbscript:If x &gt; 0 Then Refresh">Go</button>
+<a href="#" onclick="javascript:go()">JS</a>
+<input type="text" onchange="Validate Me.value">
+</body>
+</html>`
+	fset := token.NewFileSet()
+	doc, err := hta.ParseFile(fset, "admin.hta", src, 0)
+	assert.NoError(t, err)
+
+	assert.Len(t, doc.Scripts, 2)
+	assert.Equal(t, "Sub Refresh()\n  list.innerHTML = GetUsers()\nEnd Sub\n", ast.String(doc.Scripts[0].File))
+	assert.Equal(t, "btn", doc.Scripts[1].For)
+	assert.Equal(t, "onmouseover", doc.Scripts[1].Event)
+	assert.Equal(t, "btn.style.color = \"red\"\n", ast.String(doc.Scripts[1].File))
+
+	// the first script is VBScript, which makes it the default language
+	var handlers []string
+	for _, h := range doc.Handlers {
+		handlers = append(handlers, h.Element+" "+h.Event+": "+strings.TrimSpace(ast.String(h.File)))
+	}
+	assert.Equal(t, []string{
+		"body onload: Refresh",
+		"button onclick: If x > 0 Then Refresh",
+		"input onchange: Validate Me.value",
+	}, handlers)
+
+	// positions refer to the document
+	sub := doc.Scripts[0].File.Decls[0].(*ast.SubDecl)
+	assert.Equal(t, token.Position{Filename: "admin.hta", Offset: strings.Index(src, "Sub Refresh"), Line: 8, Column: 1}, fset.Position(sub.Pos()))
+	assert.Equal(t, token.Position{Filename: "admin.hta", Offset: strings.Index(src, "onclick"), Line: 19, Column: 18}, fset.Position(doc.Handlers[1].Pos))
+	cond := doc.Handlers[1].File.Stmts[0].(*ast.IfStmt).Cond
+	assert.Equal(t, token.Position{Filename: "admin.hta", Offset: strings.Index(src, "x &gt;"), Line: 19, Column: 39}, fset.Position(cond.Pos()))
+}
+
+func TestParseFileDefaultLanguage(t *testing.T) {
+	const src = `<script language="JScript">function f() {}</script>
+<body onload="f()">
+<p onclick="vbscript:MsgBox 1" ondblclick="MsgBox 2" language="VBScript">`
+	doc, err := hta.ParseFile(token.NewFileSet(), "a.html", src, 0)
+	assert.NoError(t, err)
+	assert.Len(t, doc.Scripts, 0)
+	assert.Len(t, doc.Handlers, 2)
+	assert.Equal(t, "ondblclick", doc.Handlers[1].Event)
+	assert.Equal(t, "MsgBox 2\n", ast.String(doc.Handlers[1].File))
+}
+
+func TestParseFileConfig(t *testing.T) {
+	// "MsgBox "ж"" in a code page that maps 0xE6 to "ж", as Windows-1251 does
+	var cm scanner.Charmap
+	copy(cm[:], scanner.Windows1252[:])
+	cm[0xE6-0x80] = 'ж'
+	src := "<p onclick='vbscript:MsgBox \"\xe6\"'>"
+
+	doc, err := hta.ParseFileConfig(token.NewFileSet(), "a.hta", src, &parser.Config{Charmap: &cm})
+	assert.NoError(t, err)
+	assert.Equal(t, "MsgBox \"ж\"\n", ast.String(doc.Handlers[0].File))
+}
+
+func TestParseFileManyHandlers(t *testing.T) {
+	src := strings.Repeat("<button onclick=\"vbscript:Go 1\">Go</button>\n", 500)
+	fset := token.NewFileSet()
+	doc, err := hta.ParseFile(fset, "a.hta", src, 0)
+	assert.NoError(t, err)
+	assert.Len(t, doc.Handlers, 500)
+
+	// each handler takes up the Pos space of its own code only
+	assert.Less(t, fset.Base(), 2*len(src))
+	last := doc.Handlers[499].File.Stmts[0]
+	assert.Equal(t, token.Position{Filename: "a.hta", Offset: strings.LastIndex(src, "Go 1"), Line: 500, Column: 27}, fset.Position(last.Pos()))
+}
+
+func TestParseFileErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"<script language=vbscript>\nx = \n</script>":     "a.hta:2:5: expected operand, found newline",
+		"<p>\n<b onclick='vbscript:x ='>":                 "a.hta:2:25: expected operand, found 'EOF'",
+		"<script language=vbscript>\nx = 1\n<p>":          "a.hta:1:1: expected </script> (and 1 more errors)",
+		"<p title=\"x>\n<script language=vbscript>x = \n": "a.hta:1:1: tag not terminated (and 1 more errors)",
+	} {
+		doc, err := hta.ParseFile(token.NewFileSet(), "a.hta", src, 0)
+		assert.NotNil(t, doc, src)
+		assert.EqualError(t, err, want, src)
+	}
+}
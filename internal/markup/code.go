@@ -0,0 +1,86 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package markup
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// A Mode controls how embedded code is taken from the document.
+type Mode uint
+
+const (
+	Unescape Mode = 1 << iota // replace character references
+	CDATA                     // drop CDATA section markers
+)
+
+// ParseCode parses the VBScript code in src[start:end], where src is the
+// decoded content of the document file. Lines and columns are those of
+// the document, and offsets those of the original source: file must have
+// its lines set for src and, if src was decoded, its OffsetMap.
+//
+// The code is parsed into a token.File of its own that covers only
+// src[start:end]; an OffsetMap installed for it records where the code
+// starts in the document and maps its offsets back to the original
+// source. The positions of the returned errors are mapped the same way.
+func ParseCode(fset *token.FileSet, file *token.File, src []byte, start, end int, cm Mode, mode parser.Mode) (*ast.File, scanner.ErrorList) {
+	originOf := func(offs int) int {
+		return file.Position(file.Pos(offs)).Offset
+	}
+
+	first := file.Position(file.Pos(start))
+	m := &token.OffsetMap{}
+	m.SetStart(first.Offset, first.Line, first.Column)
+	var code []byte
+	emit := func(s []byte, from, to int) {
+		code = append(code, s...)
+		m.Add(len(s), originOf(to)-originOf(from))
+	}
+
+	inCDATA := false
+	for i := start; i < end; {
+		rest := src[i:end]
+		switch {
+		case cm&CDATA != 0 && !inCDATA && bytes.HasPrefix(rest, []byte("<![CDATA[")):
+			inCDATA = true
+			emit(bytes.Repeat([]byte{' '}, len("<![CDATA[")), i, i+len("<![CDATA["))
+			i += len("<![CDATA[")
+			continue
+		case cm&CDATA != 0 && inCDATA && bytes.HasPrefix(rest, []byte("]]>")):
+			inCDATA = false
+			emit([]byte("   "), i, i+len("]]>"))
+			i += len("]]>")
+			continue
+		case cm&Unescape != 0 && !inCDATA && rest[0] == '&':
+			if r, n := entity(rest); n > 0 {
+				emit([]byte(string(r)), i, i+n)
+				i += n
+				continue
+			}
+		}
+		_, n := utf8.DecodeRune(rest)
+		emit(rest[:n], i, i+n)
+		i += n
+	}
+
+	base := fset.Base()
+	f, err := parser.ParseFile(fset, file.Name(), code, mode)
+	fset.File(token.Pos(base)).SetOffsetMap(m)
+	list, _ := err.(scanner.ErrorList)
+	for _, e := range list {
+		// the errors were reported before m was installed
+		if e.Pos.Line == 1 {
+			e.Pos.Column += first.Column - 1
+		}
+		e.Pos.Line += first.Line - 1
+		e.Pos.Offset = m.Origin(e.Pos.Offset)
+	}
+	return f, list
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package markup implements a lenient scanner for the XML and HTML
+// documents that embed VBScript, and the parsing of the embedded code
+// with positions that refer to the document.
+package markup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// If src != nil, ReadSource converts src to a []byte if possible;
+// otherwise it returns an error. If src == nil, ReadSource returns
+// the result of reading the file specified by filename.
+func ReadSource(filename string, src any) ([]byte, error) {
+	if src != nil {
+		switch s := src.(type) {
+		case string:
+			return []byte(s), nil
+		case []byte:
+			return s, nil
+		case *bytes.Buffer:
+			// is io.Reader, but src is already available in []byte form
+			if s != nil {
+				return s.Bytes(), nil
+			}
+		case io.Reader:
+			return io.ReadAll(s)
+		}
+		return nil, errors.New("invalid source")
+	}
+	return os.ReadFile(filename)
+}
+
+// The kinds of markup items.
+const (
+	EOF      = iota
+	StartTag // <name attrs> or <name attrs/>
+	EndTag   // </name>
+	ProcInst // <?name attrs?>
+	Text     // character data or a CDATA section
+)
+
+// An Attr is an attribute of a start tag or processing instruction.
+type Attr struct {
+	Name      string // lower-case name
+	Value     string // value with character references replaced, if enabled
+	Offs      int    // offset of the name
+	ValueOffs int    // offset of the raw value, after the quote if any
+	ValueEnd  int    // offset after the raw value
+}
+
+// An Item is a piece of markup returned by Scanner.Next.
+type Item struct {
+	Kind  int
+	Offs  int    // offset of the item
+	Name  string // lower-case tag or instruction name
+	Attrs []Attr
+	Empty bool   // set for <name/>
+	Text  string // character data of a Text item
+}
+
+// Attr returns the value of the attribute name, or "".
+func (it *Item) Attr(name string) string {
+	for _, a := range it.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// A Scanner reads the markup of a document. Unlike an XML decoder it
+// doesn't check that elements are well-formed, and comments and
+// declarations such as <!DOCTYPE> are skipped.
+type Scanner struct {
+	Src      []byte // source
+	Offs     int    // current reading offset
+	Unescape bool   // replace character references in text and attribute values
+
+	// Error is called with the offset and message of each error
+	// encountered; it may be nil.
+	Error func(offs int, msg string)
+}
+
+func (s *Scanner) error(offs int, msg string) {
+	if s.Error != nil {
+		s.Error(offs, msg)
+	}
+}
+
+func isNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-' || c == ':' || c == '.' || c >= utf8.RuneSelf
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func (s *Scanner) skipSpace() {
+	for s.Offs < len(s.Src) && isSpace(s.Src[s.Offs]) {
+		s.Offs++
+	}
+}
+
+func (s *Scanner) scanName() string {
+	start := s.Offs
+	for s.Offs < len(s.Src) && isNameByte(s.Src[s.Offs]) {
+		s.Offs++
+	}
+	return strings.ToLower(string(s.Src[start:s.Offs]))
+}
+
+// scanAttrs scans the attributes of a tag up to, but not including,
+// the closing "/>", "?>" or ">".
+func (s *Scanner) scanAttrs() []Attr {
+	var attrs []Attr
+	for {
+		s.skipSpace()
+		if s.Offs >= len(s.Src) || !isNameByte(s.Src[s.Offs]) {
+			return attrs
+		}
+		a := Attr{Offs: s.Offs}
+		a.Name = s.scanName()
+		s.skipSpace()
+		if s.Offs >= len(s.Src) || s.Src[s.Offs] != '=' {
+			// attribute without value
+			a.ValueOffs, a.ValueEnd = s.Offs, s.Offs
+			attrs = append(attrs, a)
+			continue
+		}
+		s.Offs++
+		s.skipSpace()
+		if s.Offs < len(s.Src) && (s.Src[s.Offs] == '"' || s.Src[s.Offs] == '\'') {
+			quote := s.Src[s.Offs]
+			a.ValueOffs = s.Offs + 1
+			end := bytes.IndexByte(s.Src[a.ValueOffs:], quote)
+			if end < 0 {
+				s.error(s.Offs, "attribute value not terminated")
+				a.ValueEnd, s.Offs = len(s.Src), len(s.Src)
+			} else {
+				a.ValueEnd = a.ValueOffs + end
+				s.Offs = a.ValueEnd + 1
+			}
+		} else {
+			a.ValueOffs = s.Offs
+			for s.Offs < len(s.Src) && !isSpace(s.Src[s.Offs]) && s.Src[s.Offs] != '>' {
+				s.Offs++
+			}
+			a.ValueEnd = s.Offs
+		}
+		a.Value = s.unescape(s.Src[a.ValueOffs:a.ValueEnd])
+		attrs = append(attrs, a)
+	}
+}
+
+// SkipTo advances past the next occurrence of str and reports whether
+// there was one; otherwise it advances to the end of the source.
+func (s *Scanner) SkipTo(str string) bool {
+	i := bytes.Index(s.Src[s.Offs:], []byte(str))
+	if i < 0 {
+		s.Offs = len(s.Src)
+		return false
+	}
+	s.Offs += i + len(str)
+	return true
+}
+
+// Next returns the next markup item.
+func (s *Scanner) Next() Item {
+	for s.Offs < len(s.Src) {
+		offs := s.Offs
+		rest := s.Src[offs:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			s.Offs += len("<!--")
+			if !s.SkipTo("-->") {
+				s.error(offs, "comment not terminated")
+			}
+		case bytes.HasPrefix(rest, []byte("<![CDATA[")):
+			start := offs + len("<![CDATA[")
+			s.Offs = start
+			if !s.SkipTo("]]>") {
+				s.error(offs, "CDATA section not terminated")
+				return Item{Kind: Text, Offs: offs, Text: string(s.Src[start:])}
+			}
+			return Item{Kind: Text, Offs: offs, Text: string(s.Src[start : s.Offs-len("]]>")])}
+		case bytes.HasPrefix(rest, []byte("<!")):
+			s.SkipTo(">")
+		case bytes.HasPrefix(rest, []byte("<?")):
+			s.Offs += len("<?")
+			it := Item{Kind: ProcInst, Offs: offs, Name: s.scanName(), Attrs: s.scanAttrs()}
+			if !s.SkipTo("?>") {
+				s.error(offs, "processing instruction not terminated")
+			}
+			return it
+		case bytes.HasPrefix(rest, []byte("</")):
+			s.Offs += len("</")
+			it := Item{Kind: EndTag, Offs: offs, Name: s.scanName()}
+			s.skipSpace()
+			s.expectTagEnd(offs)
+			return it
+		case len(rest) > 1 && rest[0] == '<' && isNameByte(rest[1]):
+			s.Offs++
+			it := Item{Kind: StartTag, Offs: offs, Name: s.scanName(), Attrs: s.scanAttrs()}
+			if bytes.HasPrefix(s.Src[s.Offs:], []byte("/")) {
+				it.Empty = true
+				s.Offs++
+			}
+			s.expectTagEnd(offs)
+			return it
+		default:
+			end := bytes.IndexByte(rest[1:], '<')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end++
+			}
+			s.Offs += end
+			return Item{Kind: Text, Offs: offs, Text: s.unescape(rest[:end])}
+		}
+	}
+	return Item{Kind: EOF, Offs: len(s.Src)}
+}
+
+func (s *Scanner) expectTagEnd(offs int) {
+	if s.Offs < len(s.Src) && s.Src[s.Offs] == '>' {
+		s.Offs++
+		return
+	}
+	s.error(offs, "tag not terminated")
+	s.SkipTo(">")
+}
+
+// RawText returns the extent of the content of the element name whose
+// start tag was just read, taken literally up to the end tag, and skips
+// the end tag. If there is no end tag, the content extends to the end
+// of the source and end is reported as an error at offs.
+func (s *Scanner) RawText(offs int, name string) (start, end int) {
+	start = s.Offs
+	end = indexFold(s.Src[start:], "</"+name)
+	if end < 0 {
+		s.error(offs, "expected </"+name+">")
+		s.Offs = len(s.Src)
+		return start, len(s.Src)
+	}
+	end += start
+	s.Offs = end
+	s.Next() // end tag
+	return start, end
+}
+
+// indexFold returns the index of the first case-insensitive occurrence
+// of the ASCII string sub in s, or -1.
+func indexFold(s []byte, sub string) int {
+	b := []byte(sub)
+	for i := 0; i+len(b) <= len(s); i++ {
+		if bytes.EqualFold(s[i:i+len(b)], b) {
+			return i
+		}
+	}
+	return -1
+}
+
+var entities = map[string]rune{"lt": '<', "gt": '>', "amp": '&', "quot": '"', "apos": '\'', "nbsp": '\u00a0'}
+
+// entity returns the character referenced by the entity or character
+// reference at the start of s, and the length of the reference. The
+// length is 0 if s doesn't start with a reference.
+func entity(s []byte) (rune, int) {
+	end := bytes.IndexByte(s, ';')
+	if len(s) < 3 || s[0] != '&' || end < 0 || end > 10 {
+		return 0, 0
+	}
+	name := string(s[1:end])
+	if r, ok := entities[name]; ok {
+		return r, end + 1
+	}
+	if strings.HasPrefix(name, "#") {
+		base, digits := 10, name[1:]
+		if strings.HasPrefix(digits, "x") || strings.HasPrefix(digits, "X") {
+			base, digits = 16, digits[1:]
+		}
+		if n, err := strconv.ParseUint(digits, base, 32); err == nil && utf8.ValidRune(rune(n)) {
+			return rune(n), end + 1
+		}
+	}
+	return 0, 0
+}
+
+// unescape returns s with character references replaced, if enabled.
+func (s *Scanner) unescape(b []byte) string {
+	if !s.Unescape || bytes.IndexByte(b, '&') < 0 {
+		return string(b)
+	}
+	var buf strings.Builder
+	for i := 0; i < len(b); i++ {
+		if r, n := entity(b[i:]); n > 0 {
+			buf.WriteRune(r)
+			i += n - 1
+			continue
+		}
+		buf.WriteByte(b[i])
+	}
+	return buf.String()
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// An ASPPage represents a parsed classic ASP page.
+type ASPPage struct {
+	File       *ast.File         // script of the page; literal HTML is written with Response.Write
+	Directives map[string]string // attributes of the <%@ %> directive, keyed by lower-case name
+	Includes   []*ASPInclude     // server-side includes, in source order
+}
+
+// An ASPInclude represents an <!--#include file="..."--> or
+// <!--#include virtual="..."--> directive.
+type ASPInclude struct {
+	Pos     token.Pos // position of "<!--"
+	Virtual bool      // set for virtual="...", a path relative to the web root
+	Path    string    // included file
+}
+
+var (
+	aspTag  = regexp.MustCompile(`(?i)<%|<!--\s*#include\s+(file|virtual)\s*=\s*"([^"]*)"\s*-->`)
+	aspAttr = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(?:"([^"]*)"|(\S+))`)
+)
+
+// An aspWrite records a Response.Write call of an ASP page: the
+// literal HTML at html, or the expressions of <%= %> if html is nil.
+type aspWrite struct {
+	html *ast.BasicLit
+}
+
+// ParseASP parses the source code of a classic ASP page and returns the
+// script of the page. The arguments have the same meaning as for
+// ParseFile.
+//
+// The VBScript code of <% %> blocks is parsed in place. Literal HTML
+// between the blocks, and <%= %> output blocks, become Response.Write
+// call statements, so that a page such as
+//
+//	<% If ok Then %><b>yes</b><% End If %>
+//
+// yields an If statement whose body writes "<b>yes</b>". The attributes
+// of <%@ %> directives and the server-side includes are recorded in the
+// ASPPage; includes are not resolved. All positions refer to the ASP page.
+//
+// A page that declares a language other than VBScript is reported as an
+// error. Otherwise the results are the same as for ParseFile.
+func ParseASP(fset *token.FileSet, filename string, src any, mode Mode) (page *ASPPage, err error) {
+	return (&Config{Mode: mode}).ParseASP(fset, filename, src)
+}
+
+// ParseASP is like the function ParseASP, with the mode taken from
+// c.Mode; pages in an ANSI code page are decoded with c.Charmap.
+func (c *Config) ParseASP(fset *token.FileSet, filename string, src any) (page *ASPPage, err error) {
+	if fset == nil {
+		panic("parser.ParseASP: no token.FileSet provided (fset == nil)")
+	}
+
+	// get source
+	text, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var p parser
+	page = &ASPPage{Directives: make(map[string]string)}
+	defer func() {
+		if e := recover(); e != nil {
+			// resume same panic if it's not a bailout
+			if _, ok := e.(bailout); !ok {
+				panic(e)
+			}
+		}
+
+		// set result values
+		if page.File == nil {
+			page.File = &ast.File{}
+		}
+
+		p.errors.Sort()
+		if p.mode&AllErrors == 0 {
+			p.errors.RemoveMultiples()
+		}
+		err = p.errors.Err()
+	}()
+
+	// The script is parsed from a copy of the page of the same length in
+	// which all but the code is blanked out, so that the positions of the
+	// copy are those of the page. The delimiters of blocks and includes
+	// become line breaks; a placeholder identifier stands in for the
+	// procedure of each Response.Write call.
+	decoded, offsets := scanner.Decode(text, c.Charmap)
+	p.file = fset.AddFile(filename, -1, len(decoded))
+	p.file.SetOffsetMap(offsets)
+	p.file.SetLinesForContent(decoded) // line breaks of the copy don't count
+	p.mode = c.Mode
+	masked, writes := p.maskASP(decoded, page)
+
+	// parse script
+	p.initScanner(masked, c.Mode, scanner.KeepLines)
+	page.File = p.parseFile()
+	ast.Inspect(page.File, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if id, ok := call.Func.(*ast.Ident); ok {
+				if w, ok := writes[id.NamePos]; ok {
+					call.Func = &ast.SelectorExpr{
+						X:   &ast.Ident{NamePos: id.NamePos, Name: "Response"},
+						Sel: &ast.Ident{NamePos: id.NamePos, Name: "Write"},
+					}
+					if w.html != nil {
+						call.Recv = []ast.Expr{w.html}
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	return
+}
+
+// maskASP returns the copy of the ASP page text that the script is parsed
+// from, and the Response.Write calls by the position of their placeholder.
+func (p *parser) maskASP(text []byte, page *ASPPage) ([]byte, map[token.Pos]aspWrite) {
+	masked := bytes.Repeat([]byte{' '}, len(text))
+	writes := make(map[token.Pos]aspWrite)
+
+	html := func(from, to int) {
+		if from < to {
+			pos := p.file.Pos(from)
+			masked[from] = 'W'
+			writes[pos] = aspWrite{html: &ast.BasicLit{ValuePos: pos, ValueEnd: p.file.Pos(to), Kind: token.STRING, Value: string(text[from:to])}}
+		}
+	}
+
+	offs := 0
+	if bytes.HasPrefix(text, []byte("\ufeff")) {
+		offs = len("\ufeff") // byte order mark
+	}
+	for offs < len(text) {
+		m := aspTag.FindSubmatchIndex(text[offs:])
+		if m == nil {
+			break
+		}
+		start, end := offs+m[0], offs+m[1]
+		html(offs, start)
+		masked[start] = '\r'
+
+		if m[2] >= 0 {
+			// include
+			page.Includes = append(page.Includes, &ASPInclude{
+				Pos:     p.file.Pos(start),
+				Virtual: strings.EqualFold(string(text[offs+m[2]:offs+m[3]]), "virtual"),
+				Path:    string(text[offs+m[4] : offs+m[5]]),
+			})
+			offs = end
+			continue
+		}
+
+		// <% %> block
+		code := end
+		close := bytes.Index(text[code:], []byte("%>"))
+		if close < 0 {
+			p.error(p.file.Pos(start), "'%>' expected")
+			close = len(text)
+		} else {
+			close += code
+			masked[close] = '\r'
+		}
+		offs = close + len("%>")
+
+		switch {
+		case code < len(text) && text[code] == '@':
+			for _, a := range aspAttr.FindAllSubmatch(text[code+1:close], -1) {
+				value := a[2]
+				if value == nil {
+					value = a[3]
+				}
+				page.Directives[strings.ToLower(string(a[1]))] = string(value)
+			}
+			if lang, ok := page.Directives["language"]; ok && !strings.EqualFold(lang, "VBScript") {
+				p.error(p.file.Pos(start), "unsupported language "+lang)
+			}
+			continue
+		case code < len(text) && text[code] == '=':
+			masked[code-1] = 'W' // the '%' of "<%="
+			writes[p.file.Pos(code-1)] = aspWrite{}
+			code++
+		}
+		copy(masked[code:close], text[code:close])
+	}
+	if offs < len(text) {
+		html(offs, len(text))
+	}
+
+	return masked, writes
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package parser_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseASP(t *testing.T) {
+	const src = `<%@ Language="VBScript" CodePage=65001 %>
+<!--#include virtual="/inc/header.asp"-->
+<html>
+<% If ok Then %><b>yes</b><% Else %>no<% End If %>
+<p><%= Server.HTMLEncode(name) %></p>
+<!-- #include file="footer.inc" -->
+<%
+Sub Greet(s)
+  Response.Write s %><br><%
+End Sub
+%>`
+	fset := token.NewFileSet()
+	page, err := parser.ParseASP(fset, "page.asp", src, 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"language": "VBScript", "codepage": "65001"}, page.Directives)
+	assert.Equal(t, []*parser.ASPInclude{
+		{Pos: page.Includes[0].Pos, Virtual: true, Path: "/inc/header.asp"},
+		{Pos: page.Includes[1].Pos, Path: "footer.inc"},
+	}, page.Includes)
+	assert.Equal(t, token.Position{Filename: "page.asp", Offset: strings.Index(src, "<!-- #include"), Line: 6, Column: 1}, fset.Position(page.Includes[1].Pos))
+
+	out := ast.String(page.File)
+	assert.Equal(t, `Sub Greet(s)
+  Response.Write s
+  Response.Write "<br>"
+End Sub
+Response.Write vbLf
+Response.Write vbLf & "<html>" & vbLf
+If ok Then
+  Response.Write "<b>yes</b>"
+Else
+  Response.Write "no"
+End If
+Response.Write vbLf & "<p>"
+Response.Write Server.HTMLEncode(name)
+Response.Write "</p>" & vbLf
+Response.Write vbLf
+`, out)
+	_, err = parser.ParseFile(token.NewFileSet(), "page.vbs", out, 0)
+	assert.NoError(t, err)
+
+	// positions refer to the page
+	write := page.File.Stmts[4].(*ast.ExprStmt).X.(*ast.CallExpr)
+	assert.Equal(t, token.Position{Filename: "page.asp", Offset: strings.Index(src, "<%=") + 1, Line: 5, Column: 5}, fset.Position(write.Pos()))
+	arg := write.Recv[0].(*ast.CallExpr)
+	assert.Equal(t, token.Position{Filename: "page.asp", Offset: strings.Index(src, "Server"), Line: 5, Column: 8}, fset.Position(arg.Pos()))
+	yes := page.File.Stmts[2].(*ast.IfStmt).Body.List[0].(*ast.ExprStmt).X.(*ast.CallExpr).Recv[0]
+	assert.Equal(t, token.Position{Filename: "page.asp", Offset: strings.Index(src, "<b>"), Line: 4, Column: 17}, fset.Position(yes.Pos()))
+	assert.Equal(t, token.Position{Filename: "page.asp", Offset: strings.Index(src, "<% Else"), Line: 4, Column: 27}, fset.Position(yes.End()))
+}
+
+func TestParseASPLiteralEnd(t *testing.T) {
+	const src = "<a href=\"x\">link</a>\r\n<% x = 1 %>"
+	fset := token.NewFileSet()
+	page, err := parser.ParseASP(fset, "a.asp", src, 0)
+	assert.NoError(t, err)
+
+	// the HTML ends where the code starts, although its quoted form is longer
+	html := page.File.Stmts[0].(*ast.ExprStmt).X.(*ast.CallExpr).Recv[0]
+	assert.Equal(t, strings.Index(src, "<%"), fset.Position(html.End()).Offset)
+}
+
+func TestParseASPByteOrderMark(t *testing.T) {
+	const page = "<p>\r\n<% x = 1 %>"
+	le := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(page)) {
+		le = append(le, byte(u), byte(u>>8))
+	}
+	for _, src := range []any{"\ufeff" + page, le} {
+		page, err := parser.ParseASP(token.NewFileSet(), "a.asp", src, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "Response.Write \"<p>\" & vbCrLf\nx = 1\n", ast.String(page.File))
+	}
+}
+
+func TestParseASPErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"<p><% x = %></p>":              "a.asp:1:11: expected operand, found newline",
+		"<p><% x = 1":                   "a.asp:1:4: '%>' expected",
+		"<%@ Language=JScript %>\nx":    "a.asp:1:1: unsupported language JScript",
+		"<% If a Then %>\n<p>\n<% x %>": "a.asp:3:8: expected 'End If', found 'EOF'",
+	} {
+		page, err := parser.ParseASP(token.NewFileSet(), "a.asp", src, 0)
+		assert.NotNil(t, page.File, src)
+		assert.EqualError(t, err, want, src)
+	}
+}
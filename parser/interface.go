@@ -0,0 +1,195 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package parser implements a parser for VBScript source files. Input may
+// be provided in a variety of forms (see the various Parse* functions); the
+// output is an abstract syntax tree (AST) representing the VBScript source.
+// The parser is invoked through one of the Parse* functions.
+//
+// The grammar files vbsLexer.g4 and vbsParser.g4 in this directory describe
+// the same language for ANTLR-based tooling. No parser generated from them
+// is included; they are kept in step with this package by hand.
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// If src != nil, readSource converts src to a []byte if possible;
+// otherwise it returns an error. If src == nil, readSource returns
+// the result of reading the file specified by filename.
+func readSource(filename string, src any) ([]byte, error) {
+	if src != nil {
+		switch s := src.(type) {
+		case string:
+			return []byte(s), nil
+		case []byte:
+			return s, nil
+		case *bytes.Buffer:
+			// is io.Reader, but src is already available in []byte form
+			if s != nil {
+				return s.Bytes(), nil
+			}
+		case io.Reader:
+			return io.ReadAll(s)
+		}
+		return nil, errors.New("invalid source")
+	}
+	return os.ReadFile(filename)
+}
+
+// A Mode value is a set of flags (or 0).
+// They control the amount of source code parsed and other optional
+// parser functionality.
+type Mode uint
+
+const (
+	ParseComments       Mode = 1 << iota // parse comments and add them to AST
+	Trace                                // print a trace of parsed productions
+	DeclarationErrors                    // report declaration errors
+	SkipProcedureBodies                  // skip the bodies of Sub, Function and Property declarations
+	AllErrors                            // report all errors (not just the first 10 on different lines)
+)
+
+// A Config controls parsing beyond the Mode flags. The Parse* functions
+// that take a Mode parse as a Config with that Mode and no Charmap.
+type Config struct {
+	Mode    Mode
+	Charmap *scanner.Charmap // code page of sources that are neither UTF-8 nor UTF-16; Windows-1252 if nil
+}
+
+// ParseFile parses the source code of a single VBScript source file and
+// returns the corresponding ast.File node. The source code may be provided
+// via the filename of the source file, or via the src parameter.
+//
+// If src != nil, ParseFile parses the source from src and the filename is
+// only used when recording position information. The type of the argument
+// for the src parameter must be string, []byte, or io.Reader.
+// If src == nil, ParseFile parses the file specified by filename.
+//
+// Sources saved as UTF-16 or in an ANSI code page are converted with
+// scanner.Decode; the offsets of the resulting positions refer to the
+// original bytes. ANSI sources are read as Windows-1252; use
+// Config.ParseFile for other code pages.
+//
+// The mode parameter controls the amount of source text parsed and
+// other optional parser functionality. Position information is
+// recorded in the file set fset, which must not be nil.
+//
+// If the source couldn't be read, the returned AST is nil and the error
+// indicates the specific failure. If the source was read but syntax
+// errors were found, the result is a partial AST (with ast.Bad* nodes
+// representing the fragments of erroneous source code). Multiple errors
+// are returned via a scanner.ErrorList which is sorted by source position.
+func ParseFile(fset *token.FileSet, filename string, src any, mode Mode) (f *ast.File, err error) {
+	return (&Config{Mode: mode}).ParseFile(fset, filename, src)
+}
+
+// ParseFile is like the function ParseFile, with the mode taken from
+// c.Mode; sources in an ANSI code page are decoded with c.Charmap.
+func (c *Config) ParseFile(fset *token.FileSet, filename string, src any) (f *ast.File, err error) {
+	if fset == nil {
+		panic("parser.ParseFile: no token.FileSet provided (fset == nil)")
+	}
+
+	// get source
+	text, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var p parser
+	defer func() {
+		if e := recover(); e != nil {
+			// resume same panic if it's not a bailout
+			if _, ok := e.(bailout); !ok {
+				panic(e)
+			}
+		}
+
+		// set result values
+		if f == nil {
+			// source is not a valid VBScript source file - satisfy
+			// ParseFile API and return a valid (but) empty *ast.File
+			f = &ast.File{}
+		}
+
+		p.errors.Sort()
+		if p.mode&AllErrors == 0 {
+			p.errors.RemoveMultiples()
+		}
+		err = p.errors.Err()
+	}()
+
+	// parse source
+	p.init(fset, filename, text, c)
+	f = p.parseFile()
+
+	return
+}
+
+// ParseExprFrom is a convenience function for parsing an expression.
+// The arguments have the same meaning as for ParseFile, but the source must
+// be a valid VBScript expression. Specifically, fset must not be nil.
+//
+// If the source couldn't be read, the returned AST is nil and the error
+// indicates the specific failure. If the source was read but syntax
+// errors were found, the result is a partial AST (with ast.Bad* nodes
+// representing the fragments of erroneous source code). Multiple errors
+// are returned via a scanner.ErrorList which is sorted by source position.
+func ParseExprFrom(fset *token.FileSet, filename string, src any, mode Mode) (expr ast.Expr, err error) {
+	if fset == nil {
+		panic("parser.ParseExprFrom: no token.FileSet provided (fset == nil)")
+	}
+
+	// get source
+	text, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var p parser
+	defer func() {
+		if e := recover(); e != nil {
+			// resume same panic if it's not a bailout
+			if _, ok := e.(bailout); !ok {
+				panic(e)
+			}
+		}
+		p.errors.Sort()
+		if p.mode&AllErrors == 0 {
+			p.errors.RemoveMultiples()
+		}
+		err = p.errors.Err()
+	}()
+
+	// parse expr
+	p.init(fset, filename, text, &Config{Mode: mode})
+	expr = p.parseExprOnly()
+
+	return
+}
+
+// ParseExpr is a convenience function for obtaining the AST of an
+// expression x. The position information recorded in the AST is
+// undefined. The filename used in error messages is the empty string.
+//
+// Operators bind as in VBScript, from loosest to tightest: Imp, Eqv,
+// Xor, Or, And, Not, the comparisons, &, + and -, Mod, \, * and /,
+// unary negation, and ^. All binary operators are left associative.
+//
+// If syntax errors were found, the result is a partial AST (with ast.Bad*
+// nodes representing the fragments of erroneous source code). Multiple
+// errors are returned via a scanner.ErrorList which is sorted by source
+// position.
+func ParseExpr(x string) (ast.Expr, error) {
+	return ParseExprFrom(token.NewFileSet(), "", []byte(x), 0)
+}
@@ -0,0 +1,1570 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/hulo-io/vbsparser/vbsconv"
+)
+
+// The parser structure holds the parser's internal state.
+type parser struct {
+	file    *token.File
+	errors  scanner.ErrorList
+	scanner scanner.Scanner
+	peeking bool // set while scanning ahead; see peek
+
+	// Tracing/debugging
+	mode   Mode // parsing mode
+	trace  bool // == (mode&Trace != 0)
+	indent int  // indentation used for tracing output
+
+	// Comments
+	comments    []*ast.CommentGroup
+	leadComment *ast.CommentGroup // last comment group that started a line; see leadDoc
+
+	// Next token
+	pos       token.Pos   // token position
+	tok       token.Token // one token look-ahead
+	lit       string      // token literal
+	stmtStart bool        // set if the token starts a statement
+	lineIf    bool        // set while parsing a single-line If; Else ends a statement there
+
+	// Constructs being parsed, identified by their first keyword
+	// (If, For, Sub, ...); see atBlockEnd
+	open []token.Token
+
+	// Declared names, if DeclarationErrors is set
+	topScope *scope
+}
+
+func (p *parser) init(fset *token.FileSet, filename string, src []byte, conf *Config) {
+	text, offsets := scanner.Decode(src, conf.Charmap)
+	p.file = fset.AddFile(filename, -1, len(text))
+	p.file.SetOffsetMap(offsets)
+	p.initScanner(text, conf.Mode, 0)
+}
+
+// initScanner prepares scanning the decoded text of p.file and reads
+// the first token.
+func (p *parser) initScanner(text []byte, mode Mode, m scanner.Mode) {
+	if mode&ParseComments != 0 {
+		m |= scanner.ScanComments
+	}
+	eh := func(pos token.Position, msg string) { p.errorAt(pos, msg) }
+	p.scanner.Init(p.file, text, eh, m)
+
+	p.mode = mode
+	p.trace = mode&Trace != 0 // for convenience (p.trace is used frequently)
+	p.next()
+}
+
+// ----------------------------------------------------------------------------
+// Scoping support
+
+// A scope holds the names declared in the script, in a class or in a
+// procedure. Names are case-insensitive.
+type scope struct {
+	outer *scope
+	decls map[string]token.Pos // keyed by lower-case name; see declare
+}
+
+func (p *parser) openScope() {
+	p.topScope = &scope{outer: p.topScope, decls: make(map[string]token.Pos)}
+}
+
+func (p *parser) closeScope() {
+	p.topScope = p.topScope.outer
+}
+
+// declare records the declaration of id in the current scope and, if
+// the DeclarationErrors mode is set, reports a name declared twice.
+// kind is the Get, Let or Set of a property, which may share its name
+// with the other accessors, or token.ILLEGAL for any other declaration.
+func (p *parser) declare(id *ast.Ident, kind token.Token) {
+	if p.mode&DeclarationErrors == 0 || p.topScope == nil {
+		return
+	}
+	name := strings.ToLower(id.Name)
+	key, conflicts := name, []string{name, "get " + name, "let " + name, "set " + name}
+	if kind != token.ILLEGAL {
+		key = strings.ToLower(kind.String()) + " " + name
+		conflicts = []string{name, key}
+	}
+	for _, k := range conflicts {
+		if prev, ok := p.topScope.decls[k]; ok {
+			p.error(id.Pos(), fmt.Sprintf("%s redeclared in this scope\n\tprevious declaration at %s", id.Name, p.file.Position(prev)))
+			return
+		}
+	}
+	p.topScope.decls[key] = id.Pos()
+}
+
+// ----------------------------------------------------------------------------
+// Parsing support
+
+func (p *parser) printTrace(a ...any) {
+	const dots = ". . . . . . . . . . . . . . . . . . . . . . . . . . . . . . . . "
+	const n = len(dots)
+	pos := p.file.Position(p.pos)
+	fmt.Printf("%5d:%3d: ", pos.Line, pos.Column)
+	i := 2 * p.indent
+	for i > n {
+		fmt.Print(dots)
+		i -= n
+	}
+	// i <= n
+	fmt.Print(dots[0:i])
+	fmt.Println(a...)
+}
+
+func trace(p *parser, msg string) *parser {
+	p.printTrace(msg, "(")
+	p.indent++
+	return p
+}
+
+// Usage pattern: defer un(trace(p, "..."))
+func un(p *parser) {
+	p.indent--
+	p.printTrace(")")
+}
+
+// Advance to the next token.
+func (p *parser) next0() {
+	// Because of one-token look-ahead, print the previous token
+	// when tracing as it provides a more readable output. The
+	// very first token (!p.pos.IsValid()) is not initialized
+	// (it is token.ILLEGAL), so don't print it.
+	if p.trace && p.pos.IsValid() {
+		s := p.tok.String()
+		switch {
+		case p.tok.IsLiteral():
+			p.printTrace(s, p.lit)
+		case p.tok.IsOperator(), p.tok.IsKeyword():
+			p.printTrace("\"" + s + "\"")
+		default:
+			p.printTrace(s)
+		}
+	}
+
+	p.pos, p.tok, p.lit = p.scanner.Scan()
+}
+
+// Consume a comment and return it.
+func (p *parser) consumeComment() *ast.Comment {
+	c := &ast.Comment{TokPos: p.pos, Tok: token.APOSTROPHE, Text: p.lit[1:]}
+	if p.lit[0] != '\'' {
+		c.Tok, c.Text = token.REM, p.lit[len("Rem"):]
+	}
+	p.next0()
+	return c
+}
+
+// Consume a group of comments, add it to the parser's comment list
+// and return it. If adjacent is set, the group extends over comments
+// on the following lines. The newline or EOF after the last comment
+// becomes the current token.
+func (p *parser) consumeCommentGroup(adjacent bool) *ast.CommentGroup {
+	var list []*ast.Comment
+	for {
+		list = append(list, p.consumeComment())
+		if !adjacent || p.tok != token.NEWLINE || p.peek() != token.COMMENT {
+			break
+		}
+		p.next0()
+	}
+
+	g := &ast.CommentGroup{List: list}
+	p.comments = append(p.comments, g)
+	return g
+}
+
+// Advance to the next non-comment token. In the process, collect
+// any comment groups encountered, and remember the last comment
+// group that starts a line as a possible lead comment. A comment
+// that follows other tokens on its line forms a group by itself.
+func (p *parser) next() {
+	p.stmtStart = !p.pos.IsValid() || p.tok == token.NEWLINE || p.tok == token.COLON
+	p.next0()
+	if p.tok == token.COMMENT {
+		g := p.consumeCommentGroup(p.stmtStart)
+		if p.stmtStart {
+			p.leadComment = g
+		}
+	}
+}
+
+// leadDoc returns the comment group that documents the current token:
+// the last comment group on the lines right before it, or nil.
+func (p *parser) leadDoc() *ast.CommentGroup {
+	if g := p.leadComment; g != nil && p.file.Line(g.End())+1 == p.file.Line(p.pos) {
+		return g
+	}
+	return nil
+}
+
+// peek returns the token following the current one without
+// consuming it.
+func (p *parser) peek() token.Token {
+	s := p.scanner // scan ahead on a copy
+	p.peeking = true
+	_, tok, _ := s.Scan()
+	p.peeking = false
+	return tok
+}
+
+// A bailout panic is raised to indicate early termination.
+type bailout struct{}
+
+// A resync panic abandons the statement or declaration being parsed
+// after a syntax error; parsing resumes after it (see parseStmtOrDecl
+// and parseBodyDecl).
+type resync struct{}
+
+func (p *parser) error(pos token.Pos, msg string) {
+	p.errorAt(p.file.Position(pos), msg)
+}
+
+func (p *parser) errorAt(epos token.Position, msg string) {
+	if p.peeking {
+		return // reported when the token is actually scanned
+	}
+
+	// If AllErrors is not set, discard errors reported on the same line
+	// as the last recorded error and stop parsing after 10 errors.
+	if p.mode&AllErrors == 0 {
+		n := len(p.errors)
+		if n > 0 && p.errors[n-1].Pos.Line == epos.Line {
+			return // discard - likely a spurious error
+		}
+		if n >= 10 {
+			panic(bailout{})
+		}
+	}
+
+	p.errors.Add(epos, msg)
+}
+
+func (p *parser) errorExpected(pos token.Pos, msg string) {
+	msg = "expected " + msg
+	if pos == p.pos {
+		// the error happened at the current position;
+		// make the error message more specific
+		switch {
+		case p.tok == token.NEWLINE:
+			msg += ", found newline"
+		case p.tok.IsLiteral():
+			// print 123 rather than 'Integer', etc.
+			msg += ", found " + p.lit
+		default:
+			msg += ", found '" + p.tok.String() + "'"
+		}
+	}
+	p.error(pos, msg)
+}
+
+// syntaxError reports that msg was expected at the current token and
+// abandons the statement being parsed.
+func (p *parser) syntaxError(msg string) {
+	p.errorExpected(p.pos, msg)
+	panic(resync{})
+}
+
+func (p *parser) expect(tok token.Token) token.Pos {
+	pos := p.pos
+	if p.tok != tok {
+		p.syntaxError("'" + tok.String() + "'")
+	}
+	p.next() // make progress
+	return pos
+}
+
+// expectEnd consumes "End" followed by tok, as in End If, and
+// returns the position of "End".
+func (p *parser) expectEnd(tok token.Token) token.Pos {
+	pos := p.pos
+	if p.tok != token.END {
+		p.syntaxError("'End " + tok.String() + "'")
+	}
+	p.next()
+	p.expect(tok)
+	return pos
+}
+
+// atStmtEnd reports whether the current token terminates a statement.
+func (p *parser) atStmtEnd() bool {
+	return p.tok == token.NEWLINE || p.tok == token.COLON || p.tok == token.EOF || p.tok == token.ELSE && p.lineIf
+}
+
+// expectTerminator consumes the newline or ':' that terminates a
+// statement and returns it as a separator. The last statement of
+// the file needs no terminator; the separator is zero then. Tokens
+// before the terminator are reported and skipped.
+func (p *parser) expectTerminator() (sep ast.Sep) {
+	if p.stmtStart {
+		return // consumed while skipping an erroneous statement
+	}
+	if !p.atStmtEnd() {
+		p.errorExpected(p.pos, "end of statement")
+		for !p.atStmtEnd() {
+			p.next()
+		}
+	}
+	if p.tok != token.EOF {
+		sep = ast.Sep{TokPos: p.pos, Tok: p.tok}
+		p.next()
+	}
+	return
+}
+
+// skipEmpty skips empty statements.
+func (p *parser) skipEmpty() {
+	for p.tok == token.NEWLINE || p.tok == token.COLON {
+		p.next()
+	}
+}
+
+// isBlockEnd reports whether tok ends a statement list. The keywords
+// that continue or close a compound statement never start a statement.
+func isBlockEnd(tok token.Token) bool {
+	switch tok {
+	case token.END, token.ELSE, token.ELSEIF, token.CASE, token.LOOP, token.NEXT, token.WEND, token.EOF:
+		return true
+	}
+	return false
+}
+
+// openBlock records that the construct starting with tok is being
+// parsed; it returns the argument for the matching closeBlock call.
+func (p *parser) openBlock(tok token.Token) int {
+	p.open = append(p.open, tok)
+	return len(p.open) - 1
+}
+
+func (p *parser) closeBlock(n int) {
+	p.open = p.open[:n]
+}
+
+// atBlockEnd reports whether the current token ends the statement list
+// being parsed: EOF, or a keyword that continues or closes one of the
+// constructs being parsed, such as Else or End If inside an If
+// statement. Any other such keyword is a misplaced statement.
+func (p *parser) atBlockEnd() bool {
+	construct := p.tok
+	switch p.tok {
+	case token.EOF:
+		return true
+	case token.END:
+		construct = p.peek()
+	case token.ELSE, token.ELSEIF:
+		construct = token.IF
+	case token.CASE:
+		construct = token.SELECT
+	case token.NEXT:
+		construct = token.FOR
+	case token.LOOP:
+		construct = token.DO
+	case token.WEND:
+		construct = token.WHILE
+	default:
+		return false
+	}
+	for _, tok := range p.open {
+		if tok == construct {
+			return true
+		}
+	}
+	return false
+}
+
+// skipStmt skips the rest of a statement abandoned at a syntax error
+// up to its terminator. It stops early at a keyword that starts a
+// statement and may close an enclosing construct.
+func (p *parser) skipStmt(from token.Pos) {
+	if p.pos == from {
+		p.next() // make progress
+	}
+	for !p.atStmtEnd() && !(p.stmtStart && isBlockEnd(p.tok)) {
+		p.next()
+	}
+}
+
+// skipPastEnd skips to the next End tok, as in End Sub, and past it.
+// It stops early at a statement that closes an enclosing construct,
+// such as End Class.
+func (p *parser) skipPastEnd(tok token.Token) {
+	for !p.atBlockEnd() || !p.stmtStart && p.tok != token.EOF {
+		if p.tok == token.END && p.peek() == tok {
+			p.next()
+			p.next()
+			return
+		}
+		p.next()
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Identifiers
+
+// isIdent reports whether tok may be used as an identifier. Apart from
+// IDENT, these are the keywords that VBScript does not reserve; they act
+// as keywords only where a statement expects them, as Step does in a For
+// statement.
+func isIdent(tok token.Token) bool {
+	switch tok {
+	case token.IDENT, token.EXPLICIT, token.GET, token.LET, token.PRESERVE, token.STEP:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseIdent() *ast.Ident {
+	pos := p.pos
+	name := "_"
+	bracketed := false
+	if isIdent(p.tok) {
+		name = p.lit
+		if p.tok == token.IDENT && strings.HasPrefix(name, "[") {
+			name, bracketed = strings.TrimSuffix(name[1:], "]"), true
+		}
+		p.next()
+	} else {
+		p.expect(token.IDENT) // use expect() error handling
+	}
+	return &ast.Ident{NamePos: pos, Name: name, Bracketed: bracketed}
+}
+
+// parseMemberName parses the name following a '.'. Member names may be
+// keywords, as in rs.Close or obj.End.
+func (p *parser) parseMemberName() *ast.Ident {
+	if p.tok != token.IDENT && p.lit != "" && !p.tok.IsLiteral() {
+		x := &ast.Ident{NamePos: p.pos, Name: p.lit}
+		p.next()
+		return x
+	}
+	return p.parseIdent()
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+func (p *parser) parseExprList() (list []ast.Expr) {
+	if p.trace {
+		defer un(trace(p, "ExpressionList"))
+	}
+
+	list = append(list, p.parseExpr())
+	for p.tok == token.COMMA {
+		p.next()
+		list = append(list, p.parseExpr())
+	}
+	return
+}
+
+// parseArgList parses the arguments of a call, any of which may be
+// omitted, as in MsgBox "Hello", , "Title".
+func (p *parser) parseArgList() (list []ast.Expr) {
+	if p.trace {
+		defer un(trace(p, "ArgumentList"))
+	}
+
+	for {
+		if p.tok == token.COMMA || p.tok == token.RPAREN || p.atStmtEnd() {
+			list = append(list, &ast.OmittedExpr{At: p.pos})
+		} else {
+			list = append(list, p.parseExpr())
+		}
+		if p.tok != token.COMMA {
+			return
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseOperand() ast.Expr {
+	if p.trace {
+		defer un(trace(p, "Operand"))
+	}
+
+	if isIdent(p.tok) {
+		return p.parseIdent()
+	}
+
+	switch p.tok {
+	case token.INTEGER, token.DOUBLE, token.DATE:
+		x := &ast.BasicLit{ValuePos: p.pos, Kind: p.tok, Value: p.lit}
+		p.next()
+		return x
+
+	case token.STRING:
+		s, err := vbsconv.Unquote(p.lit)
+		if err != nil {
+			// not terminated, which the scanner reported
+			s = strings.TrimPrefix(p.lit, `"`)
+		}
+		x := &ast.BasicLit{ValuePos: p.pos, Kind: token.STRING, Value: s}
+		p.next()
+		return x
+
+	case token.TRUE, token.FALSE:
+		x := &ast.BasicLit{ValuePos: p.pos, Kind: token.BOOLEAN, Value: p.lit}
+		p.next()
+		return x
+
+	case token.EMPTY, token.NULL, token.NOTHING:
+		x := &ast.BasicLit{ValuePos: p.pos, Kind: p.tok, Value: p.lit}
+		p.next()
+		return x
+
+	case token.LPAREN:
+		lparen := p.pos
+		p.next()
+		x := p.parseExpr()
+		rparen := p.expect(token.RPAREN)
+		return &ast.ParenExpr{Lparen: lparen, X: x, Rparen: rparen}
+
+	case token.NEW:
+		pos := p.pos
+		p.next()
+		return &ast.NewExpr{New: pos, X: p.parseIdent()}
+
+	case token.DOT:
+		// member of the object of the enclosing With statement
+		p.next()
+		return &ast.SelectorExpr{Sel: p.parseMemberName()}
+	}
+
+	pos := p.pos
+	p.errorExpected(pos, "operand")
+	if !p.atStmtEnd() {
+		p.next() // make progress
+	}
+	return &ast.BadExpr{From: pos, To: p.pos}
+}
+
+// parseCallExpr parses the parenthesized argument list following fun.
+// As VBScript does not distinguish calls from array indexing, a(i) is
+// a call as well.
+func (p *parser) parseCallExpr(fun ast.Expr) *ast.CallExpr {
+	if p.trace {
+		defer un(trace(p, "CallExpr"))
+	}
+
+	lparen := p.expect(token.LPAREN)
+	var list []ast.Expr
+	if p.tok != token.RPAREN {
+		list = p.parseArgList()
+	}
+	rparen := p.expect(token.RPAREN)
+	return &ast.CallExpr{Func: fun, Lparen: lparen, Recv: list, Rparen: rparen, Eval: isEval(fun)}
+}
+
+// isEval reports whether fun is the built-in Eval function.
+func isEval(fun ast.Expr) bool {
+	id, ok := fun.(*ast.Ident)
+	return ok && !id.Bracketed && strings.EqualFold(id.Name, "Eval")
+}
+
+// parseIndexExpr parses the parenthesized index list following x.
+func (p *parser) parseIndexExpr(x ast.Expr) ast.Expr {
+	if p.trace {
+		defer un(trace(p, "IndexExpr"))
+	}
+
+	lparen := p.expect(token.LPAREN)
+	list := p.parseExprList()
+	rparen := p.expect(token.RPAREN)
+	if len(list) == 1 {
+		return &ast.IndexExpr{X: x, Lparen: lparen, Index: list[0], Rparen: rparen}
+	}
+	return &ast.IndexListExpr{X: x, Lparen: lparen, Indices: list, Rparen: rparen}
+}
+
+// isCall reports whether x ends with a parenthesized argument or index
+// list.
+func isCall(x ast.Expr) bool {
+	switch x.(type) {
+	case *ast.CallExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// isIndexable reports whether a parenthesized list following x indexes
+// the array x evaluates to, as in Split(s, ",")(0), rather than passing
+// arguments to x.
+func isIndexable(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.CallExpr:
+		return x.Rparen.IsValid()
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// If x is non-nil, it is used as the operand of the primary expression.
+func (p *parser) parsePrimaryExpr(x ast.Expr) ast.Expr {
+	if p.trace {
+		defer un(trace(p, "PrimaryExpr"))
+	}
+
+	if x == nil {
+		x = p.parseOperand()
+	}
+	for {
+		switch p.tok {
+		case token.DOT:
+			p.next()
+			x = &ast.SelectorExpr{X: x, Sel: p.parseMemberName()}
+		case token.LPAREN:
+			if isIndexable(x) {
+				x = p.parseIndexExpr(x)
+			} else {
+				x = p.parseCallExpr(x)
+			}
+		default:
+			return x
+		}
+	}
+}
+
+func (p *parser) parseUnaryExpr() ast.Expr {
+	if p.trace {
+		defer un(trace(p, "UnaryExpr"))
+	}
+
+	switch p.tok {
+	case token.NOT:
+		pos := p.pos
+		p.next()
+		x := p.parseBinaryExpr(nil, token.NotPrec+1)
+		return &ast.UnaryExpr{OpPos: pos, Op: token.NOT, X: x}
+
+	case token.SUB, token.ADD:
+		pos, op := p.pos, p.tok
+		p.next()
+		x := p.parseBinaryExpr(nil, token.UnaryPrec+1)
+		return &ast.UnaryExpr{OpPos: pos, Op: op, X: x}
+	}
+
+	return p.parsePrimaryExpr(nil)
+}
+
+// parseBinaryExpr parses a (possibly) binary expression whose operators
+// bind at least as tightly as prec1. All binary operators are left
+// associative. If x is non-nil, it is used as the left operand.
+func (p *parser) parseBinaryExpr(x ast.Expr, prec1 int) ast.Expr {
+	if p.trace {
+		defer un(trace(p, "BinaryExpr"))
+	}
+
+	if x == nil {
+		x = p.parseUnaryExpr()
+	}
+	for {
+		op := p.tok
+		oprec := op.Precedence()
+		if oprec < prec1 || op == token.NOT {
+			return x
+		}
+		pos := p.pos
+		p.next()
+		y := p.parseBinaryExpr(nil, oprec+1)
+		x = &ast.BinaryExpr{X: x, OpPos: pos, Op: op, Y: y}
+	}
+}
+
+func (p *parser) parseExpr() ast.Expr {
+	if p.trace {
+		defer un(trace(p, "Expression"))
+	}
+
+	return p.parseBinaryExpr(nil, token.LowestPrec+1)
+}
+
+// ----------------------------------------------------------------------------
+// Statements
+
+// parseSimpleStmt parses an assignment or a procedure call, such as
+// x = 1, obj.Name = "x", MsgBox "hi" or Foo(1).
+func (p *parser) parseSimpleStmt() ast.Stmt {
+	if p.trace {
+		defer un(trace(p, "SimpleStmt"))
+	}
+
+	doc := p.leadDoc()
+	x := p.parsePrimaryExpr(nil)
+
+	if p.tok == token.EQ {
+		pos := p.pos
+		p.next()
+		return &ast.AssignStmt{Lhs: x, Assign: pos, Rhs: p.parseExpr()}
+	}
+
+	// In a call statement without Call, a single parenthesized argument
+	// following the procedure is passed by value, as in Foo (x), and
+	// starts the argument list, as in Foo (x) + 1, y.
+	if call, ok := x.(*ast.CallExpr); ok && call.Lparen.IsValid() && len(call.Recv) == 1 {
+		arg := &ast.ParenExpr{Lparen: call.Lparen, X: call.Recv[0], Rparen: call.Rparen}
+		call = &ast.CallExpr{Func: call.Func, Recv: []ast.Expr{p.parseBinaryExpr(arg, token.LowestPrec+1)}, Stmt: true, Eval: call.Eval}
+		if p.tok == token.COMMA {
+			p.next()
+			call.Recv = append(call.Recv, p.parseArgList()...)
+		}
+		return &ast.ExprStmt{Doc: doc, X: call}
+	}
+
+	if !p.atStmtEnd() || !isCall(x) {
+		// procedure call without parentheses
+		call := &ast.CallExpr{Func: x, Stmt: true, Eval: isEval(x)}
+		if !p.atStmtEnd() {
+			call.Recv = p.parseArgList()
+		}
+		x = call
+	}
+	return &ast.ExprStmt{Doc: doc, X: x}
+}
+
+func (p *parser) parseAssignStmt() *ast.AssignStmt {
+	if p.trace {
+		defer un(trace(p, "AssignStmt"))
+	}
+
+	tok, pos := p.tok, p.pos
+	p.next()
+	lhs := p.parsePrimaryExpr(nil)
+	assign := p.expect(token.EQ)
+	return &ast.AssignStmt{Tok: tok, TokPos: pos, Lhs: lhs, Assign: assign, Rhs: p.parseExpr()}
+}
+
+// parseConstDecl parses a Const declaration, which is documented by doc
+// and may be preceded by the modifier mod at modPos.
+func (p *parser) parseConstDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.ConstDecl {
+	if p.trace {
+		defer un(trace(p, "ConstDecl"))
+	}
+
+	d := &ast.ConstDecl{Doc: doc, Mod: mod, ModPos: modPos, Const: p.expect(token.CONST)}
+	for {
+		name := p.parseIdent()
+		p.declare(name, token.ILLEGAL)
+		assign := p.expect(token.EQ)
+		d.Specs = append(d.Specs, &ast.ValueSpec{Name: name, Assign: assign, Value: p.parseExpr()})
+		if p.tok != token.COMMA {
+			return d
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseCallStmt() *ast.CallStmt {
+	if p.trace {
+		defer un(trace(p, "CallStmt"))
+	}
+
+	s := &ast.CallStmt{Call: p.expect(token.CALL)}
+	x := p.parsePrimaryExpr(nil)
+	if call, ok := x.(*ast.CallExpr); ok {
+		s.Name, s.Recv = call.Func, call.Recv
+	} else {
+		s.Name = x
+	}
+	return s
+}
+
+func (p *parser) parseExitStmt() *ast.ExitStmt {
+	if p.trace {
+		defer un(trace(p, "ExitStmt"))
+	}
+
+	s := &ast.ExitStmt{Exit: p.expect(token.EXIT)}
+	switch p.tok {
+	case token.DO, token.FOR, token.FUNCTION, token.PROPERTY, token.SUB_LIT:
+		s.XPos, s.X = p.pos, p.tok
+		p.next()
+	default:
+		p.syntaxError("Do, For, Function, Property or Sub")
+	}
+	return s
+}
+
+func (p *parser) parseOnErrorStmt() *ast.OnErrorStmt {
+	if p.trace {
+		defer un(trace(p, "OnErrorStmt"))
+	}
+
+	s := &ast.OnErrorStmt{On: p.expect(token.ON)}
+	if p.tok != token.IDENT || !strings.EqualFold(p.lit, "Error") {
+		p.syntaxError("'Error'")
+	}
+	s.Error = p.pos
+	p.next()
+
+	switch p.tok {
+	case token.RESUME:
+		resume := p.pos
+		p.next()
+		s.OnErrorResume = &ast.OnErrorResume{Resume: resume, Next: p.expect(token.NEXT)}
+	case token.GOTO:
+		goTo := p.pos
+		p.next()
+		if p.tok != token.INTEGER || p.lit != "0" {
+			p.syntaxError("0")
+		}
+		s.OnErrorGoto = &ast.OnErrorGoto{GoTo: goTo, Zero: p.pos}
+		p.next()
+	default:
+		p.syntaxError("'Resume Next' or 'GoTo 0'")
+	}
+	return s
+}
+
+func (p *parser) parseOptionStmt() *ast.OptionStmt {
+	if p.trace {
+		defer un(trace(p, "OptionStmt"))
+	}
+
+	pos := p.expect(token.OPTION)
+	return &ast.OptionStmt{Option: pos, Explicit: p.expect(token.EXPLICIT)}
+}
+
+func (p *parser) parseEraseStmt() *ast.EraseStmt {
+	if p.trace {
+		defer un(trace(p, "EraseStmt"))
+	}
+
+	s := &ast.EraseStmt{Erase: p.expect(token.ERASE)}
+	s.List = p.parseExprList()
+	return s
+}
+
+func (p *parser) parseExecuteStmt() *ast.ExecuteStmt {
+	if p.trace {
+		defer un(trace(p, "ExecuteStmt"))
+	}
+
+	s := &ast.ExecuteStmt{Execute: p.pos, Global: p.tok == token.EXECUTEGLOBAL}
+	p.next()
+	s.X = p.parseExpr()
+	return s
+}
+
+func (p *parser) parseRandomizeStmt() *ast.RandomizeStmt {
+	if p.trace {
+		defer un(trace(p, "RandomizeStmt"))
+	}
+
+	s := &ast.RandomizeStmt{Randomize: p.expect(token.RANDOMIZE)}
+	if !p.atStmtEnd() {
+		s.Seed = p.parseExpr()
+	}
+	return s
+}
+
+func (p *parser) parseIfStmt() *ast.IfStmt {
+	if p.trace {
+		defer un(trace(p, "IfStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.IF))
+
+	s := &ast.IfStmt{If: p.expect(token.IF)}
+	s.Cond = p.parseExpr()
+	s.Then = p.expect(token.THEN)
+	if p.tok != token.NEWLINE && p.tok != token.EOF {
+		return p.parseLineIfStmt(s)
+	}
+	s.Body = p.parseBlock()
+
+	for p.tok == token.ELSEIF {
+		elif := &ast.IfStmt{If: p.pos}
+		p.next()
+		elif.Cond = p.parseExpr()
+		elif.Then = p.expect(token.THEN)
+		elif.Body = p.parseBlock()
+		s.ElseIf = append(s.ElseIf, elif)
+	}
+
+	if p.tok == token.ELSE {
+		s.ElsePos = p.pos
+		p.next()
+		s.Else = p.parseBlock()
+	}
+
+	s.EndIf = p.expectEnd(token.IF)
+	return s
+}
+
+// parseLineIfStmt parses the statements of the single-line If statement
+// s following Then, such as a = 1 : b = 2 Else c = 3.
+func (p *parser) parseLineIfStmt(s *ast.IfStmt) *ast.IfStmt {
+	defer func(lineIf bool) { p.lineIf = lineIf }(p.lineIf)
+	p.lineIf = true
+
+	s.SingleLine = true
+	s.Body = p.parseLineStmtList()
+	if p.tok == token.ELSE {
+		s.ElsePos = p.pos
+		p.next()
+		s.Else = p.parseLineStmtList()
+	}
+	return s
+}
+
+// parseLineStmtList parses a list of statements separated by colons
+// that ends with the line or at Else.
+func (p *parser) parseLineStmtList() *ast.BlockStmt {
+	b := &ast.BlockStmt{}
+	for {
+		b.List = append(b.List, p.parseStmt())
+		if p.tok != token.COLON {
+			return b
+		}
+		b.Seps = append(b.Seps, ast.Sep{TokPos: p.pos, Tok: p.tok})
+		p.next()
+		if p.atStmtEnd() {
+			return b // trailing colon
+		}
+	}
+}
+
+func (p *parser) parseSelectStmt() *ast.SelectStmt {
+	if p.trace {
+		defer un(trace(p, "SelectStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.SELECT))
+
+	s := &ast.SelectStmt{Select: p.expect(token.SELECT)}
+	p.expect(token.CASE)
+	s.Var = p.parseExpr()
+	p.expectTerminator()
+
+	for p.skipEmpty(); p.tok == token.CASE; p.skipEmpty() {
+		pos := p.pos
+		p.next()
+		if p.tok == token.ELSE {
+			e := &ast.CaseElseStmt{Case: pos, Else: p.pos}
+			p.next()
+			p.expectTerminator()
+			e.Body = p.parseBlock()
+			s.Else = e
+			break
+		}
+		c := &ast.CaseStmt{Case: pos, List: p.parseExprList()}
+		p.expectTerminator()
+		c.Body = p.parseBlock()
+		s.Cases = append(s.Cases, c)
+	}
+
+	s.EndSelect = p.expectEnd(token.SELECT)
+	return s
+}
+
+func (p *parser) parseForStmt() ast.Stmt {
+	if p.trace {
+		defer un(trace(p, "ForStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.FOR))
+
+	pos := p.expect(token.FOR)
+
+	if p.tok == token.EACH {
+		s := &ast.ForEachStmt{For: pos, Each: p.pos}
+		p.next()
+		s.Elem = p.parseIdent()
+		s.In = p.expect(token.IN)
+		s.Group = p.parseExpr()
+		s.Sep = p.expectTerminator()
+		s.Body = p.parseBlock()
+		s.Next = p.expect(token.NEXT)
+		return s
+	}
+
+	s := &ast.ForNextStmt{For: pos}
+	x := p.parseIdent()
+	assign := p.expect(token.EQ)
+	s.Start = &ast.BinaryExpr{X: x, OpPos: assign, Op: token.EQ, Y: p.parseExpr()}
+	s.To = p.expect(token.TO)
+	s.End_ = p.parseExpr()
+	if p.tok == token.STEP {
+		s.StepPos = p.pos
+		p.next()
+		s.Step = p.parseExpr()
+	}
+	s.Sep = p.expectTerminator()
+	s.Body = p.parseBlock()
+	s.Next = p.expect(token.NEXT)
+	return s
+}
+
+func (p *parser) parseWhileWendStmt() *ast.WhileWendStmt {
+	if p.trace {
+		defer un(trace(p, "WhileWendStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.WHILE))
+
+	s := &ast.WhileWendStmt{While: p.expect(token.WHILE)}
+	s.Cond = p.parseExpr()
+	p.expectTerminator()
+	s.Body = p.parseBlock()
+	s.Wend = p.expect(token.WEND)
+	return s
+}
+
+func (p *parser) parseDoLoopStmt() *ast.DoLoopStmt {
+	if p.trace {
+		defer un(trace(p, "DoLoopStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.DO))
+
+	s := &ast.DoLoopStmt{Do: p.expect(token.DO)}
+	if p.tok == token.WHILE || p.tok == token.UNTIL {
+		s.Pre, s.Tok, s.TokPos = true, p.tok, p.pos
+		p.next()
+		s.Cond = p.parseExpr()
+	}
+	s.Sep = p.expectTerminator()
+	s.Body = p.parseBlock()
+	s.Loop = p.expect(token.LOOP)
+	if !s.Pre && (p.tok == token.WHILE || p.tok == token.UNTIL) {
+		s.Tok, s.TokPos = p.tok, p.pos
+		p.next()
+		s.Cond = p.parseExpr()
+	}
+	return s
+}
+
+func (p *parser) parseWithStmt() *ast.WithStmt {
+	if p.trace {
+		defer un(trace(p, "WithStmt"))
+	}
+
+	defer p.closeBlock(p.openBlock(token.WITH))
+
+	s := &ast.WithStmt{With: p.expect(token.WITH)}
+	s.Cond = p.parseExpr()
+	p.expectTerminator()
+	s.Body = p.parseBlock()
+	s.EndWith = p.expectEnd(token.WITH)
+	return s
+}
+
+func (p *parser) parseStmt() ast.Stmt {
+	if p.trace {
+		defer un(trace(p, "Statement"))
+	}
+
+	switch p.tok {
+	case token.DIM:
+		return &ast.DeclStmt{Decl: p.parseDimDecl()}
+	case token.REDIM:
+		return &ast.DeclStmt{Decl: p.parseReDimDecl()}
+	case token.SET:
+		return p.parseAssignStmt()
+	case token.LET:
+		// Let x = 1, unless Let names a variable or procedure
+		if next := p.peek(); isIdent(next) || next == token.DOT {
+			return p.parseAssignStmt()
+		}
+		return p.parseSimpleStmt()
+	case token.IF:
+		return p.parseIfStmt()
+	case token.SELECT:
+		return p.parseSelectStmt()
+	case token.FOR:
+		return p.parseForStmt()
+	case token.WHILE:
+		return p.parseWhileWendStmt()
+	case token.DO:
+		return p.parseDoLoopStmt()
+	case token.WITH:
+		return p.parseWithStmt()
+	case token.CALL:
+		return p.parseCallStmt()
+	case token.EXIT:
+		return p.parseExitStmt()
+	case token.ON:
+		return p.parseOnErrorStmt()
+	case token.OPTION:
+		return p.parseOptionStmt()
+	case token.RANDOMIZE:
+		return p.parseRandomizeStmt()
+	case token.STOP:
+		s := &ast.StopStmt{Stop: p.pos}
+		p.next()
+		return s
+	case token.ERASE:
+		return p.parseEraseStmt()
+	case token.EXECUTE, token.EXECUTEGLOBAL:
+		return p.parseExecuteStmt()
+	case token.IDENT, token.EXPLICIT, token.GET, token.PRESERVE, token.STEP, token.DOT:
+		return p.parseSimpleStmt()
+	}
+
+	p.syntaxError("statement")
+	return nil
+}
+
+// parseStmtOrDecl parses a statement, or a procedure or class declaration
+// if top is set. A statement abandoned at a syntax error is skipped and
+// represented by a BadStmt.
+func (p *parser) parseStmtOrDecl(top bool) (list []ast.Stmt, decl ast.Decl) {
+	from := p.pos
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(resync); !ok {
+				panic(e)
+			}
+			p.skipStmt(from)
+			list, decl = []ast.Stmt{&ast.BadStmt{From: from, To: p.pos}}, nil
+		}
+	}()
+
+	switch p.tok {
+	case token.CONST:
+		return []ast.Stmt{&ast.DeclStmt{Decl: p.parseConstDecl(p.leadDoc(), ast.M_NONE, token.NoPos)}}, nil
+	case token.PUBLIC, token.PRIVATE, token.SUB_LIT, token.FUNCTION, token.PROPERTY, token.CLASS:
+		if !top {
+			p.syntaxError("statement")
+		}
+		d, stmts := p.parseDecl(false)
+		return stmts, d
+	}
+	return []ast.Stmt{p.parseStmt()}, nil
+}
+
+// parseStmtList parses statements up to a token that ends the enclosing
+// block. If decls is not nil, procedure and class declarations are
+// permitted and appended to *decls.
+func (p *parser) parseStmtList(decls *[]ast.Decl) (list []ast.Stmt, seps []ast.Sep) {
+	if p.trace {
+		defer un(trace(p, "StatementList"))
+	}
+
+	for p.skipEmpty(); !p.atBlockEnd(); p.skipEmpty() {
+		stmts, d := p.parseStmtOrDecl(decls != nil)
+		if d != nil {
+			*decls = append(*decls, d)
+			p.expectTerminator()
+			continue
+		}
+
+		list = append(list, stmts...)
+		for range stmts[1:] {
+			seps = append(seps, ast.Sep{})
+		}
+		seps = append(seps, p.expectTerminator())
+	}
+	return
+}
+
+func (p *parser) parseBlock() *ast.BlockStmt {
+	list, seps := p.parseStmtList(nil)
+	return &ast.BlockStmt{List: list, Seps: seps}
+}
+
+// ----------------------------------------------------------------------------
+// Declarations
+
+// parseVarSpec parses a variable name with optional array bounds, such
+// as x, a(10), m(2, 3) or the dynamic array d().
+func (p *parser) parseVarSpec() *ast.VarSpec {
+	s := &ast.VarSpec{Name: p.parseIdent()}
+	if p.tok != token.LPAREN {
+		return s
+	}
+	s.Lparen = p.pos
+	p.next()
+	if p.tok != token.RPAREN {
+		s.Bounds = p.parseExprList()
+	} else {
+		s.Dynamic = true
+	}
+	s.Rparen = p.expect(token.RPAREN)
+	return s
+}
+
+// parseVarSpecs parses a list of variables; if declare is set, their
+// names are declared in the current scope.
+func (p *parser) parseVarSpecs(declare bool) (list []*ast.VarSpec) {
+	for {
+		s := p.parseVarSpec()
+		if declare {
+			p.declare(s.Name, token.ILLEGAL)
+		}
+		list = append(list, s)
+		if p.tok != token.COMMA {
+			return
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseDimDecl() *ast.DimDecl {
+	if p.trace {
+		defer un(trace(p, "DimDecl"))
+	}
+
+	return &ast.DimDecl{Dim: p.expect(token.DIM), Specs: p.parseVarSpecs(true)}
+}
+
+func (p *parser) parseReDimDecl() *ast.ReDimDecl {
+	if p.trace {
+		defer un(trace(p, "ReDimDecl"))
+	}
+
+	d := &ast.ReDimDecl{ReDim: p.expect(token.REDIM)}
+	if p.tok == token.PRESERVE && p.peek() != token.LPAREN {
+		d.Preserve = p.pos
+		p.next()
+	}
+	d.Specs = p.parseVarSpecs(false)
+	return d
+}
+
+// parseVarDecl parses the variable list of a Public or Private
+// statement, which is documented by doc and starts with the modifier
+// mod at modPos.
+func (p *parser) parseVarDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.VarDecl {
+	if p.trace {
+		defer un(trace(p, "VarDecl"))
+	}
+
+	return &ast.VarDecl{Doc: doc, Mod: mod, ModPos: modPos, Specs: p.parseVarSpecs(true)}
+}
+
+// parseParams parses the optional parameter list of a procedure; lparen
+// and rparen are NoPos if there is none.
+func (p *parser) parseParams() (lparen token.Pos, list []*ast.Field, rparen token.Pos) {
+	if p.trace {
+		defer un(trace(p, "Parameters"))
+	}
+
+	if p.tok != token.LPAREN {
+		return
+	}
+	lparen = p.pos
+	p.next()
+	for p.tok != token.RPAREN {
+		f := &ast.Field{}
+		if p.tok == token.BYVAL || p.tok == token.BYREF {
+			f.Tok, f.TokPos = p.tok, p.pos
+			p.next()
+		}
+		f.Name = p.parseIdent()
+		p.declare(f.Name, token.ILLEGAL)
+		if p.tok == token.LPAREN {
+			f.Lparen = p.pos
+			p.next()
+			f.Rparen = p.expect(token.RPAREN)
+		}
+		list = append(list, f)
+		if p.tok != token.COMMA {
+			break
+		}
+		p.next()
+	}
+	rparen = p.expect(token.RPAREN)
+	return
+}
+
+// parseProcBody parses the body of a procedure declaration that starts
+// with tok. With SkipProcedureBodies, the body is skipped up to the
+// closing End tok and reported as an empty block.
+func (p *parser) parseProcBody(tok token.Token) *ast.BlockStmt {
+	if p.mode&SkipProcedureBodies == 0 {
+		return p.parseBlock()
+	}
+	for p.tok != token.EOF && !(p.stmtStart && p.tok == token.END && p.peek() == tok) {
+		p.next()
+	}
+	return &ast.BlockStmt{}
+}
+
+func (p *parser) parseSubDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.SubDecl {
+	if p.trace {
+		defer un(trace(p, "SubDecl"))
+	}
+
+	d := &ast.SubDecl{Doc: doc, Mod: mod, ModPos: modPos, Sub: p.expect(token.SUB_LIT)}
+	d.Name = p.parseIdent()
+	p.declare(d.Name, token.ILLEGAL)
+	p.openScope()
+	defer p.closeScope()
+	d.Lparen, d.Recv, d.Rparen = p.parseParams()
+	p.expectTerminator()
+	d.Body = p.parseProcBody(token.SUB_LIT)
+	d.EndSub = p.expectEnd(token.SUB_LIT)
+	return d
+}
+
+func (p *parser) parseFuncDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.FuncDecl {
+	if p.trace {
+		defer un(trace(p, "FuncDecl"))
+	}
+
+	d := &ast.FuncDecl{Doc: doc, Mod: mod, ModPos: modPos, Function: p.expect(token.FUNCTION)}
+	d.Name = p.parseIdent()
+	p.declare(d.Name, token.ILLEGAL)
+	p.openScope()
+	defer p.closeScope()
+	d.Lparen, d.Recv, d.Rparen = p.parseParams()
+	p.expectTerminator()
+	d.Body = p.parseProcBody(token.FUNCTION)
+	d.EndFunc = p.expectEnd(token.FUNCTION)
+	return d
+}
+
+func (p *parser) parsePropertyDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.PropertyDecl {
+	if p.trace {
+		defer un(trace(p, "PropertyDecl"))
+	}
+
+	d := &ast.PropertyDecl{Doc: doc, Mod: mod, ModPos: modPos, Property: p.expect(token.PROPERTY)}
+	switch p.tok {
+	case token.GET, token.LET, token.SET:
+		d.Tok, d.TokPos = p.tok, p.pos
+		p.next()
+	default:
+		p.syntaxError("Get, Let or Set")
+	}
+	if mod.HasDefault() && d.Tok != token.GET {
+		p.error(d.TokPos, "Default is only allowed for a Function or Property Get")
+	}
+	d.Name = p.parseIdent()
+	p.declare(d.Name, d.Tok)
+	p.openScope()
+	defer p.closeScope()
+	d.Lparen, d.Recv, d.Rparen = p.parseParams()
+	if d.Tok != token.GET && len(d.Recv) == 0 {
+		p.error(d.Name.End(), fmt.Sprintf("Property %s procedure must have at least one argument", d.Tok))
+	}
+	p.expectTerminator()
+	d.Body = p.parseProcBody(token.PROPERTY)
+	d.EndProverty = p.expectEnd(token.PROPERTY)
+	return d
+}
+
+func (p *parser) parseClassDecl(doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) *ast.ClassDecl {
+	if p.trace {
+		defer un(trace(p, "ClassDecl"))
+	}
+
+	d := &ast.ClassDecl{Doc: doc, Mod: mod, ModPos: modPos, Class: p.expect(token.CLASS)}
+	d.Name = p.parseIdent()
+	p.declare(d.Name, token.ILLEGAL)
+	p.openScope()
+	defer p.closeScope()
+	p.expectTerminator()
+
+	var def token.Pos // position of the default member
+	for p.skipEmpty(); !p.atBlockEnd(); p.skipEmpty() {
+		decl := p.parseClassMember()
+		if decl == nil {
+			p.expectTerminator()
+			continue
+		}
+		d.Decls = append(d.Decls, decl)
+		if isDefault(decl) {
+			if def.IsValid() {
+				p.error(decl.Pos(), "multiple Default members in class "+d.Name.Name)
+			}
+			def = decl.Pos()
+		}
+		p.expectTerminator()
+	}
+
+	d.EndClass = p.expectEnd(token.CLASS)
+	return d
+}
+
+// isDefault reports whether decl is declared with Public Default.
+func isDefault(decl ast.Decl) bool {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Mod.HasDefault()
+	case *ast.PropertyDecl:
+		return d.Mod.HasDefault()
+	}
+	return false
+}
+
+// parseClassMember parses a declaration in a class body. A member
+// abandoned at a syntax error is skipped and represented by a BadDecl.
+func (p *parser) parseClassMember() (decl ast.Decl) {
+	if p.trace {
+		defer un(trace(p, "ClassMember"))
+	}
+
+	from := p.pos
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(resync); !ok {
+				panic(e)
+			}
+			p.skipStmt(from)
+			decl = &ast.BadDecl{From: from, To: p.pos}
+		}
+	}()
+
+	switch p.tok {
+	case token.DIM:
+		return p.parseDimDecl()
+	case token.CONST, token.PUBLIC, token.PRIVATE, token.SUB_LIT, token.FUNCTION, token.PROPERTY:
+		decl, _ = p.parseDecl(true)
+		return decl
+	}
+	p.syntaxError("class member")
+	return nil
+}
+
+// parseBodyDecl parses a procedure or class declaration, which starts
+// with tok and is documented by doc. If a syntax error abandons the
+// declaration itself, rather than a statement of its body, the
+// declaration is skipped up to its End Sub, End Function, End Property
+// or End Class and represented by a BadDecl.
+func (p *parser) parseBodyDecl(tok token.Token, doc *ast.CommentGroup, mod ast.Modifier, modPos token.Pos) (decl ast.Decl) {
+	from := p.pos
+	if modPos.IsValid() {
+		from = modPos
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(resync); !ok {
+				panic(e)
+			}
+			p.skipPastEnd(tok)
+			decl = &ast.BadDecl{From: from, To: p.pos}
+		}
+	}()
+	defer p.closeBlock(p.openBlock(tok))
+
+	switch tok {
+	case token.SUB_LIT:
+		return p.parseSubDecl(doc, mod, modPos)
+	case token.FUNCTION:
+		return p.parseFuncDecl(doc, mod, modPos)
+	case token.PROPERTY:
+		return p.parsePropertyDecl(doc, mod, modPos)
+	}
+	return p.parseClassDecl(doc, mod, modPos)
+}
+
+// parseDecl parses a declaration that may be preceded by Public or
+// Private: a procedure or class declaration, which is returned as decl,
+// or a constant or variable declaration, which is returned as decl in a
+// class and as a DeclStmt otherwise. inClass reports whether the
+// declaration is a class member.
+func (p *parser) parseDecl(inClass bool) (decl ast.Decl, list []ast.Stmt) {
+	if p.trace {
+		defer un(trace(p, "Declaration"))
+	}
+
+	doc := p.leadDoc()
+	var mod ast.Modifier
+	var modPos token.Pos
+	switch p.tok {
+	case token.PUBLIC:
+		mod, modPos = ast.M_PUBLIC, p.pos
+		p.next()
+		if p.tok == token.IDENT && strings.EqualFold(p.lit, "Default") {
+			mod |= ast.M_DEFAULT
+			if !inClass {
+				p.error(p.pos, "Default outside of a class")
+			} else if tok := p.peek(); tok != token.FUNCTION && tok != token.PROPERTY {
+				p.error(p.pos, "Default is only allowed for a Function or Property Get")
+			}
+			p.next()
+		}
+	case token.PRIVATE:
+		mod, modPos = ast.M_PRIVATE, p.pos
+		p.next()
+	}
+
+	switch p.tok {
+	case token.SUB_LIT, token.FUNCTION:
+		return p.parseBodyDecl(p.tok, doc, mod, modPos), nil
+	case token.PROPERTY:
+		if !inClass {
+			p.error(p.pos, "Property declaration outside of a class")
+		}
+		return p.parseBodyDecl(p.tok, doc, mod, modPos), nil
+	case token.CLASS:
+		if inClass {
+			p.error(p.pos, "nested Class declaration")
+		}
+		return p.parseBodyDecl(p.tok, doc, mod, modPos), nil
+	case token.CONST:
+		d := p.parseConstDecl(doc, mod, modPos)
+		if inClass {
+			return d, nil
+		}
+		return nil, []ast.Stmt{&ast.DeclStmt{Decl: d}}
+	}
+
+	if mod.IsNone() {
+		p.syntaxError("declaration")
+	}
+	d := p.parseVarDecl(doc, mod, modPos)
+	if inClass {
+		return d, nil
+	}
+	return nil, []ast.Stmt{&ast.DeclStmt{Decl: d}}
+}
+
+// ----------------------------------------------------------------------------
+// Source files
+
+func (p *parser) parseExprOnly() (x ast.Expr) {
+	from := p.pos
+	defer func() {
+		if e := recover(); e != nil {
+			if _, ok := e.(resync); !ok {
+				panic(e)
+			}
+			x = &ast.BadExpr{From: from, To: p.pos}
+		}
+	}()
+
+	x = p.parseExpr()
+	if p.tok == token.NEWLINE {
+		p.next() // permit a trailing newline
+	}
+	if p.tok != token.EOF {
+		p.errorExpected(p.pos, "end of expression")
+	}
+	return x
+}
+
+func (p *parser) parseFile() *ast.File {
+	if p.trace {
+		defer un(trace(p, "File"))
+	}
+
+	p.openScope()
+	defer p.closeScope()
+
+	// A comment group before the first statement documents the file,
+	// unless it documents that statement.
+	f := &ast.File{}
+	p.skipEmpty()
+	if len(p.comments) > 0 && p.comments[0] != p.leadDoc() {
+		f.Doc = p.comments[0]
+	}
+
+	f.Stmts, f.Seps = p.parseStmtList(&f.Decls)
+	f.Comments = p.comments
+	return f
+}
@@ -0,0 +1,898 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package parser_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFile(t *testing.T) {
+	const src = `Option Explicit
+Dim a, b(10), c()
+Const Pi = 3.14
+x = 1 : Set y = Nothing
+MsgBox "hi", vbOKOnly
+Call obj.Run(1)
+
+Class Point
+  Private m_x
+  Public Property Get X()
+    X = m_x
+  End Property
+End Class
+
+Function Twice(ByVal n)
+  Twice = n * 2
+End Function
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.vbs", src, 0)
+	assert.NoError(t, err)
+
+	assert.Len(t, f.Stmts, 7)
+	assert.Len(t, f.Seps, 7)
+	assert.IsType(t, &ast.OptionStmt{}, f.Stmts[0])
+	assert.Equal(t, token.COLON, f.Seps[3].Tok)
+
+	dim := f.Stmts[1].(*ast.DeclStmt).Decl.(*ast.DimDecl)
+	assert.Equal(t, "Dim a, b(10), c()\n", ast.String(dim))
+
+	assert.Equal(t, &ast.DeclStmt{Decl: &ast.ConstDecl{
+		Const: 35,
+		Specs: []*ast.ValueSpec{{
+			Name:   &ast.Ident{NamePos: 41, Name: "Pi"},
+			Assign: 44,
+			Value:  &ast.BasicLit{ValuePos: 46, Kind: token.DOUBLE, Value: "3.14"},
+		}},
+	}}, f.Stmts[2])
+
+	set := f.Stmts[4].(*ast.AssignStmt)
+	assert.Equal(t, token.SET, set.Tok)
+	assert.Equal(t, &ast.BasicLit{ValuePos: set.Assign + 2, Kind: token.NOTHING, Value: "Nothing"}, set.Rhs)
+
+	call := f.Stmts[5].(*ast.ExprStmt).X.(*ast.CallExpr)
+	assert.False(t, call.Lparen.IsValid())
+	assert.Equal(t, []ast.Expr{
+		&ast.BasicLit{ValuePos: call.Recv[0].Pos(), Kind: token.STRING, Value: "hi"},
+		&ast.Ident{NamePos: call.Recv[1].Pos(), Name: "vbOKOnly"},
+	}, call.Recv)
+
+	assert.Equal(t, "Call obj.Run(1)\n", ast.String(f.Stmts[6]))
+
+	assert.Len(t, f.Decls, 2)
+	class := f.Decls[0].(*ast.ClassDecl)
+	assert.Equal(t, "Point", class.Name.Name)
+	member := class.Decls[0].(*ast.VarDecl)
+	assert.Equal(t, &ast.VarDecl{Mod: ast.M_PRIVATE, ModPos: member.Pos(), Specs: []*ast.VarSpec{{Name: &ast.Ident{NamePos: member.Pos() + 8, Name: "m_x"}}}}, member)
+	prop := class.Decls[1].(*ast.PropertyDecl)
+	assert.Equal(t, token.GET, prop.Tok)
+	assert.Equal(t, token.Position{Filename: "test.vbs", Offset: strings.Index(src, "End Property"), Line: 12, Column: 3}, fset.Position(prop.End()))
+	assert.Equal(t, token.Position{Filename: "test.vbs", Offset: strings.Index(src, "End Class"), Line: 13, Column: 1}, fset.Position(class.End()))
+
+	fn := f.Decls[1].(*ast.FuncDecl)
+	assert.Equal(t, token.BYVAL, fn.Recv[0].Tok)
+	assert.Equal(t, "Function Twice(ByVal n)\n  Twice = n * 2\nEnd Function\n", ast.String(fn))
+}
+
+func TestParseStatements(t *testing.T) {
+	const src = `If a = 1 And Not b Then
+  c = 1
+ElseIf a > 2 Then
+  c = 2
+Else
+  c = 3
+End If
+Select Case a
+  Case 1
+    x = 1
+  Case Else
+    x = 2
+End Select
+For i = 1 To 10 Step 2
+  If i > 5 Then
+    Exit For
+  End If
+Next
+For Each k In obj.Keys
+  WScript.Echo (k)
+Next
+Do While x < 10
+  x = x + 1
+Loop
+Do
+  x = x - 1
+Loop Until x = 0
+While x < 3
+  x = x + 1
+Wend
+With obj
+  .Add("k", .Count)
+End With
+On Error Resume Next
+On Error GoTo 0
+ReDim Preserve d(5)
+Randomize Timer
+Stop
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.vbs", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	ifStmt := f.Stmts[0].(*ast.IfStmt)
+	assert.Len(t, ifStmt.ElseIf, 1)
+	assert.Equal(t, token.Position{Filename: "test.vbs", Offset: strings.Index(src, "End If"), Line: 7, Column: 1}, fset.Position(ifStmt.EndIf))
+
+	exit := f.Stmts[2].(*ast.ForNextStmt).Body.List[0].(*ast.IfStmt).Body.List[0]
+	assert.Equal(t, token.Position{Filename: "test.vbs", Offset: strings.Index(src, "Exit For") + len("Exit For"), Line: 16, Column: 13}, fset.Position(exit.End()))
+
+	with := f.Stmts[7].(*ast.WithStmt)
+	add := with.Body.List[0].(*ast.ExprStmt).X.(*ast.CallExpr)
+	assert.Nil(t, add.Func.(*ast.SelectorExpr).X)
+	assert.Equal(t, token.Position{Filename: "test.vbs", Offset: strings.Index(src, ".Add"), Line: 32, Column: 3}, fset.Position(add.Pos()))
+}
+
+func TestParseExprPrecedence(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "x = -a ^ 2 * b + c & d = e Or Not f And g", 0)
+	assert.NoError(t, err)
+
+	x := f.Stmts[0].(*ast.AssignStmt).Rhs
+	or := x.(*ast.BinaryExpr)
+	assert.Equal(t, token.OR, or.Op)
+	eq := or.X.(*ast.BinaryExpr)
+	assert.Equal(t, token.EQ, eq.Op)
+	cat := eq.X.(*ast.BinaryExpr)
+	assert.Equal(t, token.BITAND, cat.Op)
+	add := cat.X.(*ast.BinaryExpr)
+	assert.Equal(t, token.ADD, add.Op)
+	mul := add.X.(*ast.BinaryExpr)
+	assert.Equal(t, token.MUL, mul.Op)
+	neg := mul.X.(*ast.UnaryExpr)
+	assert.Equal(t, token.SUB, neg.Op)
+	assert.Equal(t, token.EXP, neg.X.(*ast.BinaryExpr).Op)
+
+	and := or.Y.(*ast.BinaryExpr)
+	assert.Equal(t, token.AND, and.Op)
+	assert.Equal(t, token.NOT, and.X.(*ast.UnaryExpr).Op)
+}
+
+func TestParseEncoding(t *testing.T) {
+	src := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune("x = \"ä\"\r\ny = 1")) {
+		src = append(src, byte(u), byte(u>>8))
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "utf16.vbs", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "ä", f.Stmts[0].(*ast.AssignStmt).Rhs.(*ast.BasicLit).Value)
+	assert.Equal(t, token.Position{Filename: "utf16.vbs", Offset: 20, Line: 2, Column: 1}, fset.Position(f.Stmts[1].Pos()))
+}
+
+func TestParseCharmap(t *testing.T) {
+	// "x = "Привет"" in Windows-1251
+	src := []byte("x = \"\xcf\xf0\xe8\xe2\xe5\xf2\"\r\ny = 1")
+	var cp1251 scanner.Charmap
+	copy(cp1251[:], scanner.Windows1252[:])
+	for b := 0xC0; b <= 0xFF; b++ {
+		cp1251[b-0x80] = 'А' + rune(b-0xC0)
+	}
+
+	fset := token.NewFileSet()
+	conf := parser.Config{Charmap: &cp1251}
+	f, err := conf.ParseFile(fset, "cp1251.vbs", src)
+	assert.NoError(t, err)
+	assert.Equal(t, "Привет", f.Stmts[0].(*ast.AssignStmt).Rhs.(*ast.BasicLit).Value)
+	assert.Equal(t, token.Position{Filename: "cp1251.vbs", Offset: 14, Line: 2, Column: 1}, fset.Position(f.Stmts[1].Pos()))
+
+	page, err := conf.ParseASP(token.NewFileSet(), "cp1251.asp", append(append([]byte("<% "), src...), " %>"...))
+	assert.NoError(t, err)
+	assert.Equal(t, "Привет", page.File.Stmts[0].(*ast.AssignStmt).Rhs.(*ast.BasicLit).Value)
+}
+
+func TestParseErrors(t *testing.T) {
+	testcases := []struct {
+		src string
+		err string
+	}{
+		{"x = ", "test.vbs:1:5: expected operand, found 'EOF'"},
+		{"Sub a\n", "test.vbs:1:7: expected 'End Sub', found 'EOF'"},
+		{"x = (1\n", "test.vbs:1:7: expected ')', found newline"},
+		{"x = 1 y = 2", "test.vbs:1:7: expected end of statement, found y"},
+		{"If x Then\nEnd Sub", "test.vbs:2:1: expected statement, found 'End'"},
+		{"Next", "test.vbs:1:1: expected statement, found 'Next'"},
+		{"Property Get a\nEnd Property", "test.vbs:1:1: Property declaration outside of a class"},
+		{"x = \"abc", "test.vbs:1:5: string literal not terminated"},
+		{"Select Case a\nCase Else x = 1\nEnd Select", "test.vbs:2:11: expected end of statement, found x"},
+	}
+	for _, tc := range testcases {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "test.vbs", tc.src, 0)
+		assert.NotNil(t, f, tc.src)
+		assert.EqualError(t, err, tc.err, tc.src)
+	}
+}
+
+// group renders x with every operation parenthesized.
+func group(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.BinaryExpr:
+		return "(" + group(x.X) + " " + x.Op.String() + " " + group(x.Y) + ")"
+	case *ast.UnaryExpr:
+		if x.Op == token.NOT {
+			return "(Not " + group(x.X) + ")"
+		}
+		return "(" + x.Op.String() + group(x.X) + ")"
+	case *ast.SelectorExpr:
+		if x.X == nil {
+			return "." + x.Sel.Name
+		}
+		return group(x.X) + "." + x.Sel.Name
+	case *ast.CallExpr:
+		return group(x.Func) + "(" + groupList(x.Recv) + ")"
+	case *ast.IndexExpr:
+		return group(x.X) + "[" + group(x.Index) + "]"
+	case *ast.IndexListExpr:
+		return group(x.X) + "[" + groupList(x.Indices) + "]"
+	case *ast.NewExpr:
+		return "(New " + group(x.X) + ")"
+	}
+	return ast.ExprStr(x)
+}
+
+func groupList(list []ast.Expr) string {
+	s := make([]string, len(list))
+	for i, x := range list {
+		s[i] = group(x)
+	}
+	return strings.Join(s, ", ")
+}
+
+func TestParseExpr(t *testing.T) {
+	testcases := []struct {
+		src, want string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"1 - 2 - 3", "((1 - 2) - 3)"},
+		{"2 ^ 3 ^ 2", "((2 ^ 3) ^ 2)"},
+		{"-2 ^ 2", "(-(2 ^ 2))"},
+		{"-a * b", "((-a) * b)"},
+		{"2 ^ -1", "(2 ^ (-1))"},
+		{"a * b / c", "((a * b) / c)"},
+		{"a / b \\ c", "((a / b) \\ c)"},
+		{"a \\ b Mod c", "((a \\ b) Mod c)"},
+		{"a Mod b + c", "((a Mod b) + c)"},
+		{`"x" & a + 1`, `("x" & (a + 1))`},
+		{"a & b = c", "((a & b) = c)"},
+		{"a <> b And c <= d", "((a <> b) And (c <= d))"},
+		{"x Is Nothing Or y", "((x Is Nothing) Or y)"},
+		{"Not a = b", "(Not (a = b))"},
+		{"Not a And b", "((Not a) And b)"},
+		{"a And Not b Or c", "((a And (Not b)) Or c)"},
+		{"a Or b Xor c Eqv d Imp e", "((((a Or b) Xor c) Eqv d) Imp e)"},
+		{"(a Or b) And c", "((a Or b) And c)"},
+		{"obj.Items(1).Name", "obj.Items(1).Name"},
+		{"Split(s, \",\")(0)", "Split(s, \",\")[0]"},
+		{"f()(1, 2)", "f()[1, 2]"},
+		{"New RegExp", "(New RegExp)"},
+		{".Count + 1", "(.Count + 1)"},
+		{"rs.Fields.Item(\"x\").Value\n", "rs.Fields.Item(\"x\").Value"},
+	}
+	for _, tc := range testcases {
+		x, err := parser.ParseExpr(tc.src)
+		if assert.NoError(t, err, tc.src) {
+			assert.Equal(t, tc.want, group(x), tc.src)
+		}
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"a +":      "1:4: expected operand, found 'EOF'",
+		"a b":      "1:3: expected end of expression, found b",
+		"f(1":      "1:4: expected ')', found 'EOF'",
+		"x = 1\ny": "2:1: expected end of expression, found y",
+	} {
+		x, err := parser.ParseExpr(src)
+		assert.NotNil(t, x, src)
+		assert.EqualError(t, err, want, src)
+	}
+}
+
+func TestParseExprFrom(t *testing.T) {
+	fset := token.NewFileSet()
+	x, err := parser.ParseExprFrom(fset, "expr.vbs", "a _\n  + b", 0)
+	assert.NoError(t, err)
+	bin := x.(*ast.BinaryExpr)
+	assert.Equal(t, token.Position{Filename: "expr.vbs", Offset: 6, Line: 2, Column: 3}, fset.Position(bin.OpPos))
+}
+
+func TestParseRecovery(t *testing.T) {
+	const src = `x = 1 +
+Sub A
+  y = )
+  z = 2
+End Sub
+Function B(
+End Function
+Class C
+  Private d
+  Public Sub
+    e = 1
+End Class
+w = 3
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.vbs", src, 0)
+	assert.EqualError(t, err, "test.vbs:1:8: expected operand, found newline (and 3 more errors)")
+
+	list := err.(scanner.ErrorList)
+	assert.Equal(t, []string{
+		"test.vbs:1:8: expected operand, found newline",
+		"test.vbs:3:7: expected operand, found ')'",
+		"test.vbs:6:12: expected 'IDENT', found newline",
+		"test.vbs:10:13: expected 'IDENT', found newline",
+	}, errorStrings(list))
+
+	assert.Len(t, f.Stmts, 2)
+	assert.IsType(t, &ast.BadExpr{}, f.Stmts[0].(*ast.AssignStmt).Rhs.(*ast.BinaryExpr).Y)
+	assert.Equal(t, "w = 3\n", ast.String(f.Stmts[1]))
+
+	assert.Len(t, f.Decls, 3)
+	sub := f.Decls[0].(*ast.SubDecl)
+	assert.IsType(t, &ast.AssignStmt{}, sub.Body.List[0])
+	assert.IsType(t, &ast.BadExpr{}, sub.Body.List[0].(*ast.AssignStmt).Rhs)
+	assert.Equal(t, "z = 2\n", ast.String(sub.Body.List[1]))
+	bad := f.Decls[1].(*ast.BadDecl)
+	assert.Equal(t, strings.Index(src, "Function"), fset.Position(bad.From).Offset)
+	class := f.Decls[2].(*ast.ClassDecl)
+	assert.Len(t, class.Decls, 2)
+	assert.IsType(t, &ast.VarDecl{}, class.Decls[0])
+	assert.IsType(t, &ast.BadDecl{}, class.Decls[1])
+}
+
+func TestParseStrayStatements(t *testing.T) {
+	const src = "Next\nx = 1\nLoop\nWend\ny = 2"
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.Equal(t, []string{
+		"1:1: expected statement, found 'Next'",
+		"3:1: expected statement, found 'Loop'",
+		"4:1: expected statement, found 'Wend'",
+	}, errorStrings(err.(scanner.ErrorList)))
+	assert.Len(t, f.Stmts, 5)
+	assert.IsType(t, &ast.BadStmt{}, f.Stmts[0])
+	assert.Equal(t, "y = 2\n", ast.String(f.Stmts[4]))
+}
+
+func TestParseAllErrors(t *testing.T) {
+	src := strings.Repeat("x = )\n", 15)
+
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.Len(t, err.(scanner.ErrorList), 10)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors)
+	assert.Len(t, err.(scanner.ErrorList), 15)
+}
+
+func errorStrings(list scanner.ErrorList) []string {
+	s := make([]string, len(list))
+	for i, e := range list {
+		s[i] = e.Error()
+	}
+	return s
+}
+
+func TestParseComments(t *testing.T) {
+	const src = `' Script header
+
+' Greets the user.
+Sub Greet() ' trailing
+  ' say hello
+  ' twice
+  MsgBox "hi"
+End Sub
+
+Rem Runs the script.
+Greet
+x = 1 : ' after a colon
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	assert.NoError(t, err)
+
+	texts := make([]string, len(f.Comments))
+	for i, g := range f.Comments {
+		texts[i] = g.Text()
+	}
+	assert.Equal(t, []string{"Script header", "Greets the user.", "trailing", "say hello\ntwice", "Runs the script.", "after a colon"}, texts)
+
+	assert.Same(t, f.Comments[0], f.Doc)
+	sub := f.Decls[0].(*ast.SubDecl)
+	assert.Same(t, f.Comments[1], sub.Doc)
+	assert.Same(t, f.Comments[3], sub.Body.List[0].(*ast.ExprStmt).Doc)
+	assert.Same(t, f.Comments[4], f.Stmts[0].(*ast.ExprStmt).Doc)
+	assert.Equal(t, token.REM, f.Comments[4].List[0].Tok)
+	assert.Len(t, f.Stmts, 2)
+
+	cmap := ast.NewCommentMap(fset, f, f.Comments)
+	assert.Equal(t, []*ast.CommentGroup{f.Comments[5]}, cmap[f.Stmts[1]])
+
+	// without ParseComments, comments are skipped
+	f, err = parser.ParseFile(fset, "", src, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, f.Doc)
+	assert.Empty(t, f.Comments)
+	assert.Nil(t, f.Decls[0].(*ast.SubDecl).Doc)
+}
+
+func TestParseDeclarationErrors(t *testing.T) {
+	const src = `Dim a, b(3)
+Const A = 1
+Sub F(x, X)
+  Dim b
+  Dim y, y
+End Sub
+Function f()
+End Function
+Class C
+  Private v
+  Public Property Get v
+  End Property
+  Public Property Get P
+  End Property
+  Public Property Let P(value)
+  End Property
+  Public Property Get p
+  End Property
+End Class
+`
+	_, err := parser.ParseFile(token.NewFileSet(), "decl.vbs", src, 0)
+	assert.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "decl.vbs", src, parser.DeclarationErrors)
+	assert.Equal(t, []string{
+		"decl.vbs:2:7: A redeclared in this scope\n\tprevious declaration at decl.vbs:1:5",
+		"decl.vbs:3:10: X redeclared in this scope\n\tprevious declaration at decl.vbs:3:7",
+		"decl.vbs:5:10: y redeclared in this scope\n\tprevious declaration at decl.vbs:5:7",
+		"decl.vbs:7:10: f redeclared in this scope\n\tprevious declaration at decl.vbs:3:5",
+		"decl.vbs:11:23: v redeclared in this scope\n\tprevious declaration at decl.vbs:10:11",
+		"decl.vbs:17:23: p redeclared in this scope\n\tprevious declaration at decl.vbs:13:23",
+	}, errorStrings(err.(scanner.ErrorList)))
+}
+
+func TestParseSkipProcedureBodies(t *testing.T) {
+	const src = `Function F(a)
+  If a Then
+    F = 1 +
+  End If
+End Function
+Class C
+  Sub S
+    Bogus Bogus (
+  End Sub
+End Class
+x = F(1)
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipProcedureBodies)
+	assert.NoError(t, err)
+	assert.Len(t, f.Decls, 2)
+	assert.Empty(t, f.Decls[0].(*ast.FuncDecl).Body.List)
+	assert.Empty(t, f.Decls[1].(*ast.ClassDecl).Decls[0].(*ast.SubDecl).Body.List)
+	assert.Len(t, f.Stmts, 1)
+}
+
+func TestParseTrace(t *testing.T) {
+	r, w, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, err = parser.ParseFile(token.NewFileSet(), "", "x = 1", parser.Trace)
+	os.Stdout = stdout
+	w.Close()
+	assert.NoError(t, err)
+
+	out, _ := io.ReadAll(r)
+	assert.Equal(t, `    1:  1: File (
+    1:  1: . StatementList (
+    1:  1: . . Statement (
+    1:  1: . . . SimpleStmt (
+    1:  1: . . . . PrimaryExpr (
+    1:  1: . . . . . Operand (
+    1:  1: . . . . . . IDENT x
+    1:  3: . . . . . )
+    1:  3: . . . . )
+    1:  3: . . . . "="
+    1:  5: . . . . Expression (
+    1:  5: . . . . . BinaryExpr (
+    1:  5: . . . . . . UnaryExpr (
+    1:  5: . . . . . . . PrimaryExpr (
+    1:  5: . . . . . . . . Operand (
+    1:  5: . . . . . . . . . Integer 1
+    1:  6: . . . . . . . . )
+    1:  6: . . . . . . . )
+    1:  6: . . . . . . )
+    1:  6: . . . . . )
+    1:  6: . . . . )
+    1:  6: . . . )
+    1:  6: . . )
+    1:  6: . )
+    1:  6: )
+`, string(out))
+}
+
+func TestParseSingleLineIf(t *testing.T) {
+	const src = `If a Then b = 1 : c = 2 Else d = 3
+If x > 0 Then Exit Sub
+If a Then If b Then c = 1 Else c = 2
+If a Then b = 1 : Else c = 1 :
+If a Then
+  b = 1
+Else
+  c = 1
+End If
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+	assert.Len(t, f.Stmts, 5)
+
+	s := f.Stmts[0].(*ast.IfStmt)
+	assert.True(t, s.SingleLine)
+	assert.False(t, s.EndIf.IsValid())
+	assert.Len(t, s.Body.List, 2)
+	assert.Equal(t, []ast.Sep{{TokPos: s.Body.List[0].End() + 1, Tok: token.COLON}}, s.Body.Seps)
+	assert.Equal(t, strings.Index(src, "Else"), fset.Position(s.ElsePos).Offset)
+	assert.Equal(t, strings.Index(src, "\n"), fset.Position(s.End()).Offset)
+
+	// Else belongs to the innermost If
+	outer := f.Stmts[2].(*ast.IfStmt)
+	assert.Nil(t, outer.Else)
+	assert.Len(t, outer.Body.List[0].(*ast.IfStmt).Else.List, 1)
+
+	block := f.Stmts[4].(*ast.IfStmt)
+	assert.False(t, block.SingleLine)
+	assert.True(t, block.ElsePos.IsValid())
+
+	_, err = parser.ParseFile(fset, "", "If a Then b = 1 ElseIf c Then d = 1", 0)
+	assert.EqualError(t, err, "1:17: expected end of statement, found 'ElseIf'")
+}
+
+func TestParseLoopHeaderSeps(t *testing.T) {
+	const src = `For Each x In d.Keys : WScript.Echo x : Next
+For i = 1 To 3 : s = s & i
+Next
+Do While i > 0 : i = i - 1 : Loop
+Do : Loop Until True
+For Each x In d
+  WScript.Echo x : Next
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	s := f.Stmts[0].(*ast.ForEachStmt)
+	assert.Equal(t, ast.Sep{TokPos: s.Group.End() + 1, Tok: token.COLON}, s.Sep)
+	assert.Equal(t, token.NEWLINE, f.Stmts[4].(*ast.ForEachStmt).Sep.Tok)
+}
+
+func TestParseCallStatements(t *testing.T) {
+	const src = `Err.Raise 6
+MsgBox "x", vbOKOnly
+Foo (x)
+Foo (a + 1) * 2, b
+Foo ((a)), (b)
+Foo
+Foo()
+obj.Add("k", 1)
+y = Foo(x) + (1)
+Call Foo((x))
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	call := func(i int) *ast.CallExpr { return f.Stmts[i].(*ast.ExprStmt).X.(*ast.CallExpr) }
+
+	raise := call(0)
+	assert.True(t, raise.Stmt)
+	assert.False(t, raise.Lparen.IsValid())
+	assert.Equal(t, "Err.Raise", ast.ExprStr(raise.Func))
+
+	// a single parenthesized argument is passed by value
+	byVal := call(2)
+	assert.True(t, byVal.Stmt)
+	assert.Equal(t, []ast.Expr{&ast.ParenExpr{
+		Lparen: byVal.Func.End() + 1,
+		X:      &ast.Ident{NamePos: byVal.Func.End() + 2, Name: "x"},
+		Rparen: byVal.Func.End() + 3,
+	}}, byVal.Recv)
+	assert.Equal(t, byVal.Recv[0].End(), byVal.End())
+
+	args := call(3)
+	assert.Len(t, args.Recv, 2)
+	mul := args.Recv[0].(*ast.BinaryExpr)
+	assert.Equal(t, token.MUL, mul.Op)
+	assert.IsType(t, &ast.ParenExpr{}, mul.X)
+
+	assert.True(t, call(5).Stmt)
+	assert.Empty(t, call(5).Recv)
+	assert.False(t, call(6).Stmt)
+	assert.False(t, call(7).Stmt)
+	assert.Len(t, call(7).Recv, 2)
+
+	rhs := f.Stmts[8].(*ast.AssignStmt).Rhs.(*ast.BinaryExpr)
+	assert.False(t, rhs.X.(*ast.CallExpr).Stmt)
+	assert.IsType(t, &ast.ParenExpr{}, rhs.Y)
+}
+
+// The keywords that VBScript does not reserve may name variables and
+// procedures.
+func TestParseUnreservedKeywords(t *testing.T) {
+	const src = `Dim step, get, let, explicit, preserve
+For step = 1 To 10 Step step
+Next
+ReDim Preserve preserve(5)
+ReDim Preserve(5)
+Let get = explicit
+let = 2
+get.Close
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	assert.Equal(t, "step", f.Stmts[1].(*ast.ForNextStmt).Step.(*ast.Ident).Name)
+	assert.True(t, f.Stmts[2].(*ast.DeclStmt).Decl.(*ast.ReDimDecl).Preserve.IsValid())
+	redim := f.Stmts[3].(*ast.DeclStmt).Decl.(*ast.ReDimDecl)
+	assert.False(t, redim.Preserve.IsValid())
+	assert.Equal(t, "Preserve", redim.Specs[0].Name.Name)
+	assert.Equal(t, token.LET, f.Stmts[4].(*ast.AssignStmt).Tok)
+	assert.Equal(t, "let", f.Stmts[5].(*ast.AssignStmt).Lhs.(*ast.Ident).Name)
+}
+
+func TestParseOmittedArgs(t *testing.T) {
+	const src = `MsgBox "Hello", , "Title"
+x = f(1, , 3)
+Call g(, 1)
+h a,
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	msgBox := f.Stmts[0].(*ast.ExprStmt).X.(*ast.CallExpr)
+	assert.Len(t, msgBox.Recv, 3)
+	assert.Equal(t, &ast.OmittedExpr{At: msgBox.Recv[0].End() + 2}, msgBox.Recv[1])
+	assert.IsType(t, &ast.OmittedExpr{}, f.Stmts[2].(*ast.CallStmt).Recv[0])
+	assert.IsType(t, &ast.OmittedExpr{}, f.Stmts[3].(*ast.ExprStmt).X.(*ast.CallExpr).Recv[1])
+}
+
+func TestParseDynamicCode(t *testing.T) {
+	const src = `Erase a
+Erase b, c.d
+Execute "x = 1"
+ExecuteGlobal ("Sub F : End Sub")
+y = Eval("1 + 2") * 3
+cmd.Execute
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, src, ast.String(f))
+
+	erase := f.Stmts[1].(*ast.EraseStmt)
+	assert.Len(t, erase.List, 2)
+	assert.Equal(t, token.Pos(9), erase.Pos())
+	assert.Equal(t, token.Pos(9+len("Erase b, c.d")), erase.End())
+
+	exec := f.Stmts[2].(*ast.ExecuteStmt)
+	assert.False(t, exec.Global)
+	assert.Equal(t, "\"x = 1\"", ast.ExprStr(exec.X))
+	global := f.Stmts[3].(*ast.ExecuteStmt)
+	assert.True(t, global.Global)
+	assert.IsType(t, &ast.ParenExpr{}, global.X)
+
+	eval := f.Stmts[4].(*ast.AssignStmt).Rhs.(*ast.BinaryExpr).X.(*ast.CallExpr)
+	assert.True(t, eval.Eval)
+
+	// a method named Execute is an ordinary call
+	call := f.Stmts[5].(*ast.ExprStmt).X.(*ast.CallExpr)
+	assert.False(t, call.Eval)
+	assert.Equal(t, "cmd.Execute", ast.ExprStr(call.Func))
+}
+
+func TestParseConstDecl(t *testing.T) {
+	const src = `' Limits
+Public Const A = 1, B = "x"
+Private Const C = -1
+Const D = A + 1
+
+Class Box
+  ' Box size
+  Private Const Size = 10
+  Public Function Area()
+    Const Two = 2
+    Area = Size * Two
+  End Function
+End Class
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	assert.NoError(t, err)
+
+	decl := f.Stmts[0].(*ast.DeclStmt).Decl.(*ast.ConstDecl)
+	assert.True(t, decl.Mod.HasPublic())
+	assert.Equal(t, "Limits", decl.Doc.Text())
+	assert.Len(t, decl.Specs, 2)
+	assert.Equal(t, token.Pos(10), decl.Pos())
+	assert.Equal(t, token.Pos(10+len(`Public Const A = 1, B = "x"`)), decl.End())
+	assert.Equal(t, "B", decl.Specs[1].Name.Name)
+
+	class := f.Decls[0].(*ast.ClassDecl)
+	size := class.Decls[0].(*ast.ConstDecl)
+	assert.True(t, size.Mod.HasPrivate())
+	assert.Equal(t, "Box size", size.Doc.Text())
+
+	assert.Equal(t, `Class Box
+  ' Box size
+  Private Const Size = 10
+  Public Function Area()
+    Const Two = 2
+    Area = Size * Two
+  End Function
+End Class
+' Limits
+Public Const A = 1, B = "x"
+Private Const C = -1
+Const D = A + 1
+`, ast.String(f))
+}
+
+func TestParseVarDecl(t *testing.T) {
+	const src = `Dim a, b(9, n + 1), c()
+Public Count, Items(10)
+Private buf()
+ReDim Preserve c(Count)
+
+Class Stack
+  ' Stack storage
+  Private m_items(), m_top
+  Public Size
+  Dim m_grid(3, 3)
+End Class
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	assert.NoError(t, err)
+
+	dim := f.Stmts[0].(*ast.DeclStmt).Decl.(*ast.DimDecl)
+	assert.Len(t, dim.Specs, 3)
+	assert.Nil(t, dim.Specs[0].Bounds)
+	assert.Equal(t, "n + 1", ast.ExprStr(dim.Specs[1].Bounds[1]))
+	assert.Equal(t, token.Pos(1+len("Dim a, b(9, n + 1)")), dim.Specs[1].End())
+	assert.True(t, dim.Specs[2].Dynamic)
+	assert.Nil(t, dim.Specs[2].Bounds)
+	assert.Equal(t, token.Pos(1+len("Dim a, b(9, n + 1), c()")), dim.End())
+
+	public := f.Stmts[1].(*ast.DeclStmt).Decl.(*ast.VarDecl)
+	assert.True(t, public.Mod.HasPublic())
+	assert.Equal(t, "Items", public.Specs[1].Name.Name)
+	assert.Len(t, public.Specs[1].Bounds, 1)
+	private := f.Stmts[2].(*ast.DeclStmt).Decl.(*ast.VarDecl)
+	assert.True(t, private.Mod.HasPrivate())
+	assert.True(t, private.Specs[0].Dynamic)
+
+	redim := f.Stmts[3].(*ast.DeclStmt).Decl.(*ast.ReDimDecl)
+	assert.True(t, redim.Preserve.IsValid())
+	assert.Equal(t, "Count", ast.ExprStr(redim.Specs[0].Bounds[0]))
+
+	class := f.Decls[0].(*ast.ClassDecl)
+	assert.Len(t, class.Decls, 3)
+	items := class.Decls[0].(*ast.VarDecl)
+	assert.Equal(t, "Stack storage", items.Doc.Text())
+	assert.IsType(t, &ast.DimDecl{}, class.Decls[2])
+
+	assert.Equal(t, `Class Stack
+  Private m_items(), m_top
+  Public Size
+  Dim m_grid(3, 3)
+End Class
+Dim a, b(9, n + 1), c()
+Public Count, Items(10)
+Private buf()
+ReDim Preserve c(Count)
+`, ast.String(f))
+}
+
+func TestParseSelectStmt(t *testing.T) {
+	const src = `Select Case LCase(ext)
+  Case "vbs", "wsf", "hta"
+    kind = 1
+  Case n + 1
+  Case Else: kind = 0
+End Select
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+
+	s := f.Stmts[0].(*ast.SelectStmt)
+	assert.Len(t, s.Cases, 2)
+	assert.Equal(t, []string{`"vbs"`, `"wsf"`, `"hta"`}, []string{ast.ExprStr(s.Cases[0].List[0]), ast.ExprStr(s.Cases[0].List[1]), ast.ExprStr(s.Cases[0].List[2])})
+	assert.Equal(t, token.Pos(strings.Index(src, `"hta"`)+len(`"hta"`)+1), s.Cases[0].List[2].End())
+	assert.Len(t, s.Cases[1].List, 1)
+	assert.Equal(t, token.Pos(strings.Index(src, "Else")+1), s.Else.Else)
+	assert.Equal(t, "kind = 0\n", ast.String(s.Else.Body.List[0]))
+
+	assert.Equal(t, `Select Case LCase(ext)
+  Case "vbs", "wsf", "hta"
+    kind = 1
+  Case n + 1
+  Case Else
+    kind = 0
+End Select
+`, ast.String(f))
+}
+
+func TestParseSignatures(t *testing.T) {
+	const src = `Class Dictionary
+  Public Default Function Item(ByVal key)
+  End Function
+  Public Property Let Value(ByRef i, ByVal v)
+  End Property
+  Private Property Get Count
+  End Property
+End Class
+Sub Sort(a(), ByVal n)
+End Sub
+Sub Main()
+End Sub
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+
+	class := f.Decls[0].(*ast.ClassDecl)
+	item := class.Decls[0].(*ast.FuncDecl)
+	assert.True(t, item.Mod.HasPublic())
+	assert.True(t, item.Mod.HasDefault())
+	assert.Equal(t, token.BYVAL, item.Recv[0].Tok)
+	value := class.Decls[1].(*ast.PropertyDecl)
+	assert.Len(t, value.Recv, 2)
+	assert.Same(t, value.Recv[1], value.Value())
+	count := class.Decls[2].(*ast.PropertyDecl)
+	assert.False(t, count.Lparen.IsValid())
+	assert.Nil(t, count.Value())
+
+	sort := f.Decls[1].(*ast.SubDecl)
+	assert.True(t, sort.Recv[0].IsArray())
+	assert.Equal(t, token.Pos(strings.Index(src, "a()")+len("a()")+1), sort.Recv[0].End())
+	assert.False(t, sort.Recv[1].IsArray())
+
+	assert.Equal(t, src, ast.String(f))
+}
+
+func TestParseDefaultErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"Public Default Function F()\nEnd Function":                                                                        "1:8: Default outside of a class",
+		"Class C\n  Public Default Sub S()\n  End Sub\nEnd Class":                                                          "2:10: Default is only allowed for a Function or Property Get",
+		"Class C\n  Public Default Property Let P(v)\n  End Property\nEnd Class":                                           "2:27: Default is only allowed for a Function or Property Get",
+		"Class C\n  Public Default Function A()\n  End Function\n  Public Default Function B()\n  End Function\nEnd Class": "4:3: multiple Default members in class C",
+		"Class C\n  Property Set P()\n  End Property\nEnd Class":                                                           "2:17: Property Set procedure must have at least one argument",
+	} {
+		_, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+		assert.EqualError(t, err, want, src)
+	}
+}
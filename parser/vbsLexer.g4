@@ -6,6 +6,15 @@
  */
 lexer grammar vbsLexer;
 
+// VBScript keywords are case-insensitive: 'Dim', 'DIM' and 'dim' all match.
+options {
+	caseInsensitive = true;
+}
+
+// Comments must precede IDENT so that a lone 'Rem' is a comment.
+COMMENT: ('\'' | 'Rem' [ \t]) ~[\r\n]* -> channel(HIDDEN);
+EMPTY_REM: 'Rem' -> type(COMMENT), channel(HIDDEN);
+
 CALL: 'Call';
 CLASS: 'Class';
 END: 'End';
@@ -20,6 +29,7 @@ NEXT: 'Next';
 GOTO: 'GoTo';
 PUBLIC: 'Public';
 PRIVATE: 'Private';
+DEFAULT: 'Default';
 STOP: 'Stop';
 DO: 'Do';
 SELECT: 'Select';
@@ -33,28 +43,42 @@ IS: 'Is';
 ERASE: 'Erase';
 EXECUTE: 'Execute';
 EXECUTEGLOBAL: 'ExecuteGlobal';
+RANDOMIZE: 'Randomize';
 FOR: 'For';
 EACH: 'Each';
 IN: 'In';
 TO: 'To';
 STEP: 'Step';
 FUNCTION: 'Function';
-WITH: 'WITH';
-SUB_LIT: 'SUB';
-PROPERTY: 'PROPERTY';
+WITH: 'With';
+SUB_LIT: 'Sub';
+PROPERTY: 'Property';
 GET: 'Get';
 LET: 'Let';
 SET: 'Set';
 BYVAL: 'ByVal';
 BYREF: 'ByRef';
+NEW: 'New';
+ME: 'Me';
 NOTHING: 'Nothing';
+EMPTY: 'Empty';
+NULL: 'Null';
+TRUE: 'True';
+FALSE: 'False';
 OPTION: 'Option';
 EXPLICIT: 'Explicit';
 IF: 'If';
 THEN: 'Then';
-ELSEIF: 'ELSEIF';
+ELSEIF: 'ElseIf';
 ELSE: 'Else';
 
+AND: 'And';
+OR: 'Or';
+NOT: 'Not';
+XOR: 'Xor';
+EQV: 'Eqv';
+IMP: 'Imp';
+
 ADD: '+';
 SUB: '-';
 MUL: '*';
@@ -62,15 +86,31 @@ DIV: '/';
 IDIV: '\\';
 MOD: 'Mod';
 EXP: '^';
+CONCAT: '&';
 ASSIGN: '=';
 
 LT: '<';
 GT: '>';
+LE: '<=';
+GE: '>=';
+NEQ: '<>';
 
 COMMA: ',';
+DOT: '.';
+COLON: ':';
 LPAREN: '(';
 RPAREN: ')';
-NUM: '-'? [0-9]+ ('.' [0-9]+)?;
-IDENT: [a-zA-Z_][a-zA-Z0-9_]*;
 
-WS: [ \t\n\r]+ -> skip;
\ No newline at end of file
+NUM: ([0-9]+ ('.' [0-9]*)? | '.' [0-9]+) ('E' [+-]? [0-9]+)?;
+HEX: '&H' [0-9A-F]+ '&'?;
+OCT: '&O' [0-7]+ '&'?;
+STRING: '"' (~["\r\n] | '""')* '"';
+DATE: '#' ~[#\r\n]+ '#';
+IDENT: [\p{L}_] [\p{L}\p{Nd}_]* | '[' ~[\]\r\n]* ']';
+
+// Statements end at line breaks, so these are tokens; a line ending in
+// " _" continues on the next line.
+NEWLINE: '\r'? '\n' | '\r';
+LINE_CONTINUATION: [ \t]+ '_' [ \t]* ('\r'? '\n' | '\r') -> skip;
+
+WS: [ \t]+ -> skip;
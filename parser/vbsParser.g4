@@ -10,90 +10,250 @@ options {
 	tokenVocab = vbsLexer;
 }
 
-file: stmt*;
+file: block stmt? EOF;
 
-block: stmt*;
+// A block is a sequence of statements, each terminated by a line break
+// or a colon. The terminator of a compound statement is part of the
+// enclosing block.
+block: (stmt? eos)*;
+
+eos: NEWLINE | COLON;
 
 stmt:
-	dimDecl
-	| subDecl
+	optionStmt
 	| dimDecl
+	| reDimDecl
+	| constDecl
+	| subDecl
 	| funcDecl
 	| propertyDecl
-	| constStmt
-	| withStmt
+	| classDecl
+	| memberDecl
+	| ifStmt
+	| selectStmt
 	| forStmt
-	| callStmt
+	| forEachStmt
+	| doLoopStmt
+	| whileWendStmt
+	| withStmt
 	| exitStmt
-	| funcDecl
+	| onErrorStmt
+	| callStmt
+	| eraseStmt
+	| executeStmt
+	| randomizeStmt
+	| stopStmt
+	| setStmt
 	| assignStmt
-	| optionStmt
-	| whileWendStmt
-	| forEachStmt
-	| selectStmt
-	| doWhileStmt
-	| ifStmt;
-
-classDecl: CLASS IDENT classBody END CLASS;
+	| callSubStmt;
 
-classMemeber: (PUBLIC | PRIVATE) expr (COMMA expr)*;
+// Declarations
 
-classBody:
-	classMemeber
-	| dimDecl
-	| funcDecl
-	| propertyDecl
-	| subDecl;
-
-propertyDecl:
-	export? PROPERTY (GET | SET | LET) IDENT block END PROPERTY;
+optionStmt: OPTION EXPLICIT;
 
-dimDecl: DIM IDENT subscripts? (COMMA IDENT subscripts?)*;
+dimDecl: DIM varSpec (COMMA varSpec)*;
 
-reDimDecl: REDIM PRESERVE? expr;
+varSpec: ident (LPAREN (expr (COMMA expr)*)? RPAREN)?;
 
-subDecl: export? SUB IDENT block END SUB;
+reDimDecl: REDIM PRESERVE? reDimSpec (COMMA reDimSpec)*;
 
-constStmt: export? CONST IDENT ASSIGN;
+reDimSpec: ident LPAREN expr (COMMA expr)* RPAREN;
 
-export: PUBLIC | PRIVATE;
+constDecl: export? CONST constSpec (COMMA constSpec)*;
 
-subscripts: LPAREN RPAREN;
+constSpec: ident ASSIGN expr;
 
-funcDecl: export? FUNCTION IDENT END FUNCTION;
+// Public and Private variables, at file level or in a class.
+memberDecl: export varSpec (COMMA varSpec)*;
 
-onErrorStmt: ON ERROR (onErrorResume | onErrorGoto);
+export: PUBLIC DEFAULT? | PRIVATE;
 
-onErrorResume: RESUME NEXT;
+subDecl: export? SUB_LIT ident params? eos block END SUB_LIT;
 
-onErrorGoto: GOTO NUM;
+funcDecl: export? FUNCTION ident params? eos block END FUNCTION;
 
-callStmt: CALL IDENT;
+propertyDecl:
+	export? PROPERTY (GET | LET | SET) ident params? eos block END PROPERTY;
 
-forStmt: FOR expr TO expr (STEP expr) block NEXT;
+params: LPAREN (param (COMMA param)*)? RPAREN;
 
-exitStmt: EXIT (DO | FOR | FUNCTION | PROPERTY | SUB_LIT)?;
+param: (BYVAL | BYREF)? ident (LPAREN RPAREN)?;
 
-assignStmt: export? (CONST | SET) IDENT ASSIGN expr;
+classDecl: CLASS ident eos classBody END CLASS;
 
-withStmt: WITH expr block END WITH;
+classBody: (classMember? eos)*;
 
-optionStmt: OPTION EXPLICIT;
+classMember:
+	memberDecl
+	| dimDecl
+	| subDecl
+	| funcDecl
+	| propertyDecl;
 
-ifStmt:
-	IF expr THEN block (ELSEIF expr THEN block)* (ELSE block)? END IF;
+// Statements
 
-forEachStmt: FOR EACH expr IN expr block NEXT expr?;
+ifStmt: blockIfStmt | lineIfStmt;
 
-doWhileStmt: doWhilePreStmt | doWhilePostStmt;
+blockIfStmt:
+	IF expr THEN eos block elseIfClause* (ELSE block)? END IF;
 
-doWhilePreStmt: DO (WHILE | UNTIL) expr block LOOP;
+elseIfClause: ELSEIF expr THEN block;
 
-doWhilePostStmt: DO block LOOP (WHILE | UNTIL) expr;
+// The single-line form: If c Then a : b Else c
+lineIfStmt: IF expr THEN lineStmts (ELSE lineStmts)?;
 
-whileWendStmt: WHILE expr block WEND;
+lineStmts: stmt (COLON stmt?)*;
 
 selectStmt:
-	SELECT CASE expr (CASE expr block)* (CASE ELSE block)? END SELECT;
+	SELECT CASE expr eos+ caseClause* (CASE ELSE block)? END SELECT;
 
-expr: IDENT | NUM | NOTHING;
\ No newline at end of file
+caseClause: CASE expr (COMMA expr)* block;
+
+forStmt:
+	FOR ident ASSIGN expr TO expr (STEP expr)? eos block NEXT;
+
+forEachStmt: FOR EACH ident IN expr eos block NEXT;
+
+doLoopStmt:
+	DO ((WHILE | UNTIL) expr)? eos block LOOP ((WHILE | UNTIL) expr)?;
+
+whileWendStmt: WHILE expr eos block WEND;
+
+withStmt: WITH expr eos block END WITH;
+
+exitStmt: EXIT (DO | FOR | FUNCTION | PROPERTY | SUB_LIT);
+
+onErrorStmt: ON ERROR (RESUME NEXT | GOTO NUM);
+
+callStmt: CALL postfixExpr;
+
+eraseStmt: ERASE ident (COMMA ident)*;
+
+executeStmt: (EXECUTE | EXECUTEGLOBAL) expr;
+
+randomizeStmt: RANDOMIZE expr?;
+
+stopStmt: STOP;
+
+setStmt: SET postfixExpr ASSIGN expr;
+
+// Let is optional, and an identifier where no assignment follows it.
+assignStmt: LET? postfixExpr ASSIGN expr;
+
+// A call without parentheses: Foo a, , b
+callSubStmt: postfixExpr argList?;
+
+argList: expr (COMMA expr?)* | (COMMA expr?)+;
+
+// Expressions, from the highest to the lowest precedence.
+
+expr:
+	postfixExpr								# operandExpr
+	| expr EXP expr							# expExpr
+	| (SUB | ADD) expr						# unaryExpr
+	| expr (MUL | DIV) expr					# mulExpr
+	| expr IDIV expr						# idivExpr
+	| expr MOD expr							# modExpr
+	| expr (ADD | SUB) expr					# addExpr
+	| expr CONCAT expr						# concatExpr
+	| expr (ASSIGN | NEQ | LT | GT | LE | GE | IS) expr	# compareExpr
+	| NOT expr								# notExpr
+	| expr AND expr							# andExpr
+	| expr OR expr							# orExpr
+	| expr XOR expr							# xorExpr
+	| expr EQV expr							# eqvExpr
+	| expr IMP expr							# impExpr;
+
+postfixExpr: primary (DOT anyName | LPAREN argList? RPAREN)*;
+
+primary:
+	ident
+	| ME
+	| NEW ident
+	| DOT anyName // in a With block
+	| LPAREN expr RPAREN
+	| literal;
+
+literal:
+	NUM
+	| HEX
+	| OCT
+	| STRING
+	| DATE
+	| TRUE
+	| FALSE
+	| NOTHING
+	| EMPTY
+	| NULL;
+
+// Keywords that are not reserved may name variables and procedures.
+ident:
+	IDENT
+	| ERROR
+	| EXPLICIT
+	| GET
+	| LET
+	| DEFAULT
+	| PRESERVE
+	| STEP;
+
+// After a dot, any word names a member.
+anyName: ident | keyword;
+
+keyword:
+	CALL
+	| CLASS
+	| END
+	| CONST
+	| DIM
+	| REDIM
+	| ON
+	| RESUME
+	| NEXT
+	| GOTO
+	| PUBLIC
+	| PRIVATE
+	| STOP
+	| DO
+	| SELECT
+	| CASE
+	| WHILE
+	| UNTIL
+	| EXIT
+	| WEND
+	| LOOP
+	| IS
+	| ERASE
+	| EXECUTE
+	| EXECUTEGLOBAL
+	| RANDOMIZE
+	| FOR
+	| EACH
+	| IN
+	| TO
+	| FUNCTION
+	| WITH
+	| SUB_LIT
+	| PROPERTY
+	| SET
+	| BYVAL
+	| BYREF
+	| NEW
+	| ME
+	| NOTHING
+	| EMPTY
+	| NULL
+	| TRUE
+	| FALSE
+	| OPTION
+	| IF
+	| THEN
+	| ELSEIF
+	| ELSE
+	| AND
+	| OR
+	| NOT
+	| XOR
+	| EQV
+	| IMP
+	| MOD;
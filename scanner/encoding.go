@@ -0,0 +1,98 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package scanner
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// A Charmap describes a single-byte code page by mapping the bytes
+// 0x80 through 0xFF to Unicode; the bytes below 0x80 are ASCII.
+type Charmap [128]rune
+
+// Windows1252 is the Windows ANSI code page used for Western European
+// languages, the encoding Notepad writes as "ANSI" on most systems.
+var Windows1252 = &Charmap{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+	0xA0, '¡', '¢', '£', '¤', '¥', '¦', '§', '¨', '©', 'ª', '«', '¬', 0xAD, '®', '¯',
+	'°', '±', '²', '³', '´', 'µ', '¶', '·', '¸', '¹', 'º', '»', '¼', '½', '¾', '¿',
+	'À', 'Á', 'Â', 'Ã', 'Ä', 'Å', 'Æ', 'Ç', 'È', 'É', 'Ê', 'Ë', 'Ì', 'Í', 'Î', 'Ï',
+	'Ð', 'Ñ', 'Ò', 'Ó', 'Ô', 'Õ', 'Ö', '×', 'Ø', 'Ù', 'Ú', 'Û', 'Ü', 'Ý', 'Þ', 'ß',
+	'à', 'á', 'â', 'ã', 'ä', 'å', 'æ', 'ç', 'è', 'é', 'ê', 'ë', 'ì', 'í', 'î', 'ï',
+	'ð', 'ñ', 'ò', 'ó', 'ô', 'õ', 'ö', '÷', 'ø', 'ù', 'ú', 'û', 'ü', 'ý', 'þ', 'ÿ',
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Decode converts the source src to UTF-8, the encoding the Scanner
+// expects. A UTF-16LE or UTF-16BE source is recognized by its byte
+// order mark. A source without byte order mark that is not valid UTF-8
+// is decoded as the single-byte code page cm, or Windows1252 if cm is nil.
+//
+// If src has to be converted, Decode returns the converted text together
+// with an OffsetMap that relates its offsets to those of src; the map is
+// meant to be installed with token.File.SetOffsetMap for the file created
+// for text. Otherwise src is returned unchanged with a nil map. A byte
+// order mark is kept as a UTF-8 byte order mark, which the Scanner skips.
+func Decode(src []byte, cm *Charmap) ([]byte, *token.OffsetMap) {
+	switch {
+	case bytes.HasPrefix(src, bomUTF8):
+		return src, nil
+	case bytes.HasPrefix(src, bomUTF16LE):
+		return decodeUTF16(src, func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 })
+	case bytes.HasPrefix(src, bomUTF16BE):
+		return decodeUTF16(src, func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) })
+	case utf8.Valid(src):
+		return src, nil
+	}
+
+	if cm == nil {
+		cm = Windows1252
+	}
+	m := &token.OffsetMap{}
+	buf := make([]byte, 0, len(src)+len(src)/2)
+	for _, b := range src {
+		r := rune(b)
+		if b >= utf8.RuneSelf {
+			r = cm[b-utf8.RuneSelf]
+		}
+		n := len(buf)
+		buf = utf8.AppendRune(buf, r)
+		m.Add(len(buf)-n, 1)
+	}
+	return buf, m
+}
+
+func decodeUTF16(src []byte, unit func([]byte) uint16) ([]byte, *token.OffsetMap) {
+	m := &token.OffsetMap{}
+	buf := make([]byte, 0, len(src))
+	for i := 0; i < len(src); {
+		r, w := utf8.RuneError, 1
+		if i+1 < len(src) {
+			r, w = rune(unit(src[i:])), 2
+			if utf16.IsSurrogate(r) {
+				r = utf8.RuneError
+				if i+3 < len(src) {
+					if dec := utf16.DecodeRune(rune(unit(src[i:])), rune(unit(src[i+2:]))); dec != utf8.RuneError {
+						r, w = dec, 4
+					}
+				}
+			}
+		}
+		n := len(buf)
+		buf = utf8.AppendRune(buf, r)
+		m.Add(len(buf)-n, w)
+		i += w
+	}
+	return buf, m
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package scanner_test
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func utf16le(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func utf16be(s string) []byte {
+	b := []byte{0xFE, 0xFF}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return b
+}
+
+type posElt struct {
+	tok    token.Token
+	lit    string
+	pos    string
+	offset int
+}
+
+func scanDecoded(t *testing.T, src []byte, cm *scanner.Charmap) []posElt {
+	text, m := scanner.Decode(src, cm)
+	file := token.NewFileSet().AddFile("test.vbs", -1, len(text))
+	file.SetOffsetMap(m)
+
+	var s scanner.Scanner
+	s.Init(file, text, func(pos token.Position, msg string) {
+		t.Errorf("%s: %s", pos, msg)
+	}, 0)
+	var res []posElt
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		p := file.Position(pos)
+		res = append(res, posElt{tok, lit, p.String(), p.Offset})
+	}
+	return res
+}
+
+func TestDecodeUTF16(t *testing.T) {
+	src := "x = \"héllo 𝄞\"\r\nMsgBox x"
+	expected := []posElt{
+		{token.IDENT, "x", "test.vbs:1:4", 2},
+		{token.EQ, "", "test.vbs:1:6", 6},
+		{token.STRING, "\"héllo 𝄞\"", "test.vbs:1:8", 10},
+		{token.NEWLINE, "", "test.vbs:1:21", 30},
+		{token.IDENT, "MsgBox", "test.vbs:2:1", 34},
+		{token.IDENT, "x", "test.vbs:2:8", 48},
+	}
+	assert.Equal(t, expected, scanDecoded(t, utf16le(src), nil))
+	assert.Equal(t, expected, scanDecoded(t, utf16be(src), nil))
+}
+
+func TestDecodeCodePage(t *testing.T) {
+	// "s = "café – 5€"" in Windows-1252
+	src := []byte("s = \"caf\xe9 \x96 5\x80\" & t")
+	assert.Equal(t, []posElt{
+		{token.IDENT, "s", "test.vbs:1:1", 0},
+		{token.EQ, "", "test.vbs:1:3", 2},
+		{token.STRING, "\"café – 5€\"", "test.vbs:1:5", 4},
+		{token.BITAND, "", "test.vbs:1:22", 16},
+		{token.IDENT, "t", "test.vbs:1:24", 18},
+	}, scanDecoded(t, src, nil))
+
+	var cyrillic scanner.Charmap
+	copy(cyrillic[:], scanner.Windows1252[:])
+	cyrillic[0xE0-0x80] = 'а'
+	assert.Equal(t, "\"а\"", scanDecoded(t, []byte("\"\xe0\""), &cyrillic)[0].lit)
+}
+
+func TestDecodeUTF8(t *testing.T) {
+	src := []byte("\xef\xbb\xbfx = \"é\"")
+	text, m := scanner.Decode(src, nil)
+	assert.Equal(t, src, text)
+	assert.Nil(t, m)
+	assert.Equal(t, posElt{token.IDENT, "x", "test.vbs:1:4", 3}, scanDecoded(t, src, nil)[0])
+}
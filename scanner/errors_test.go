@@ -0,0 +1,49 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package scanner_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorList(t *testing.T) {
+	var list scanner.ErrorList
+	assert.NoError(t, list.Err())
+	assert.Equal(t, "no errors", list.Error())
+
+	list.Add(token.Position{Filename: "b.vbs", Line: 1, Column: 1}, "b")
+	list.Add(token.Position{Filename: "a.vbs", Line: 2, Column: 5}, "second")
+	list.Add(token.Position{Filename: "a.vbs", Line: 2, Column: 1}, "first")
+	list.Add(token.Position{Filename: "a.vbs", Line: 1, Column: 9}, "z")
+	list.Add(token.Position{Filename: "a.vbs", Line: 1, Column: 9}, "y")
+	assert.Equal(t, 5, list.Len())
+
+	list.Sort()
+	var buf bytes.Buffer
+	scanner.PrintError(&buf, list)
+	assert.Equal(t, "a.vbs:1:9: y\na.vbs:1:9: z\na.vbs:2:1: first\na.vbs:2:5: second\nb.vbs:1:1: b\n", buf.String())
+
+	list.RemoveMultiples()
+	assert.Len(t, list, 3)
+	assert.EqualError(t, list.Err(), "a.vbs:1:9: y (and 2 more errors)")
+
+	list.Reset()
+	assert.NoError(t, list.Err())
+}
+
+func TestErrorString(t *testing.T) {
+	assert.Equal(t, "msg", scanner.Error{Msg: "msg"}.Error())
+	assert.Equal(t, "x.vbs: msg", scanner.Error{Pos: token.Position{Filename: "x.vbs"}, Msg: "msg"}.Error())
+	assert.Equal(t, "3:4: msg", scanner.Error{Pos: token.Position{Line: 3, Column: 4}, Msg: "msg"}.Error())
+
+	var buf bytes.Buffer
+	scanner.PrintError(&buf, errors.New("plain"))
+	assert.Equal(t, "plain\n", buf.String())
+}
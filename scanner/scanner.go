@@ -0,0 +1,510 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package scanner implements a scanner for VBScript source text.
+// It takes a []byte as source which can then be tokenized
+// through repeated calls to the Scan method.
+//
+// The scanner works on UTF-8 text; sources saved as UTF-16 or in
+// an ANSI code page are converted with Decode first.
+package scanner
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// An ErrorHandler may be provided to Scanner.Init. If a syntax error is
+// encountered and a handler was installed, the handler is called with a
+// position and an error message. The position points to the beginning of
+// the offending token.
+type ErrorHandler func(pos token.Position, msg string)
+
+// A Scanner holds the scanner's internal state while processing
+// a given text. It can be allocated as part of another data
+// structure but must be initialized via Init before use.
+type Scanner struct {
+	// immutable state
+	file *token.File  // source file handle
+	src  []byte       // source
+	err  ErrorHandler // error reporting; or nil
+	mode Mode         // scanning mode
+
+	// scanning state
+	ch        rune // current character
+	offset    int  // character offset
+	rdOffset  int  // reading offset (position after current character)
+	stmtStart bool // set if the next token starts a statement
+
+	// public state - ok to modify
+	ErrorCount int // number of errors encountered
+}
+
+const (
+	bom = 0xFEFF // byte order mark, only permitted as very first character
+	eof = -1     // end of file
+)
+
+// Read the next Unicode char into s.ch.
+// s.ch < 0 means end-of-file.
+func (s *Scanner) next() {
+	if s.rdOffset < len(s.src) {
+		s.offset = s.rdOffset
+		if (s.ch == '\n' || s.ch == '\r' && s.src[s.offset] != '\n') && s.mode&KeepLines == 0 {
+			s.file.AddLine(s.offset)
+		}
+		r, w := rune(s.src[s.rdOffset]), 1
+		switch {
+		case r == 0:
+			s.error(s.offset, "illegal character NUL")
+		case r >= utf8.RuneSelf:
+			// not ASCII
+			r, w = utf8.DecodeRune(s.src[s.rdOffset:])
+			if r == utf8.RuneError && w == 1 {
+				s.error(s.offset, "illegal UTF-8 encoding")
+			} else if r == bom && s.offset > 0 {
+				s.error(s.offset, "illegal byte order mark")
+			}
+		}
+		s.rdOffset += w
+		s.ch = r
+	} else {
+		s.offset = len(s.src)
+		if (s.ch == '\n' || s.ch == '\r') && s.mode&KeepLines == 0 {
+			s.file.AddLine(s.offset)
+		}
+		s.ch = eof
+	}
+}
+
+// peek returns the byte following the most recently read character without
+// advancing the scanner. If the scanner is at EOF, peek returns 0.
+func (s *Scanner) peek() byte {
+	if s.rdOffset < len(s.src) {
+		return s.src[s.rdOffset]
+	}
+	return 0
+}
+
+// A mode value is a set of flags (or 0).
+// They control scanner behavior.
+type Mode uint
+
+const (
+	ScanComments Mode = 1 << iota // return comments as COMMENT tokens
+	KeepLines                     // don't add line information; the file's lines are already set
+)
+
+// Init prepares the scanner s to tokenize the text src by setting the
+// scanner at the beginning of src. The scanner uses the file set file
+// for position information and it adds line information for each line.
+// It is ok to re-use the same file when re-scanning the same file as
+// line information which is already present is ignored. Init causes a
+// panic if the file size does not match the src size.
+//
+// Calls to Scan will invoke the error handler err if they encounter a
+// syntax error and err is not nil. Also, for each error encountered,
+// the Scanner field ErrorCount is incremented by one. The mode parameter
+// determines how comments are handled.
+//
+// Note that Init may call err if there is an error in the first character
+// of the file.
+func (s *Scanner) Init(file *token.File, src []byte, err ErrorHandler, mode Mode) {
+	// Explicitly initialize all fields since a scanner may be reused.
+	if file.Size() != len(src) {
+		panic(fmt.Sprintf("file size (%d) does not match src len (%d)", file.Size(), len(src)))
+	}
+	s.file = file
+	s.src = src
+	s.err = err
+	s.mode = mode
+
+	s.ch = ' '
+	s.offset = 0
+	s.rdOffset = 0
+	s.stmtStart = true
+	s.ErrorCount = 0
+
+	s.next()
+	if s.ch == bom {
+		s.next() // ignore BOM at file beginning
+	}
+}
+
+func (s *Scanner) error(offs int, msg string) {
+	if s.err != nil {
+		s.err(s.file.Position(s.file.Pos(offs)), msg)
+	}
+	s.ErrorCount++
+}
+
+func (s *Scanner) errorf(offs int, format string, args ...any) {
+	s.error(offs, fmt.Sprintf(format, args...))
+}
+
+func isLetter(ch rune) bool {
+	return 'a' <= lower(ch) && lower(ch) <= 'z' || ch == '_' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+func isDigit(ch rune) bool {
+	return isDecimal(ch) || ch >= utf8.RuneSelf && unicode.IsDigit(ch)
+}
+
+func isDecimal(ch rune) bool { return '0' <= ch && ch <= '9' }
+
+func lower(ch rune) rune { return ('a' - 'A') | ch }
+
+func (s *Scanner) scanIdentifier() string {
+	offs := s.offset
+	for isLetter(s.ch) || isDigit(s.ch) {
+		s.next()
+	}
+	return string(s.src[offs:s.offset])
+}
+
+// scanBracketedIdentifier scans an escaped identifier such as
+// [my odd name], which may contain any character except ']' and
+// line breaks.
+func (s *Scanner) scanBracketedIdentifier() string {
+	// opening [ already consumed
+	offs := s.offset - 1
+
+	for {
+		ch := s.ch
+		if ch == '\n' || ch == '\r' || ch < 0 {
+			s.error(offs, "bracketed identifier not terminated")
+			break
+		}
+		s.next()
+		if ch == ']' {
+			break
+		}
+	}
+
+	return string(s.src[offs:s.offset])
+}
+
+func digitVal(ch rune) int {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch - '0')
+	case 'a' <= lower(ch) && lower(ch) <= 'f':
+		return int(lower(ch) - 'a' + 10)
+	}
+	return 16 // larger than any legal digit val
+}
+
+// scanComment scans the remainder of a comment up to, but not including,
+// the end of the line. offs is the offset of the comment marker, which
+// has already been consumed.
+func (s *Scanner) scanComment(offs int) string {
+	for s.ch != '\n' && s.ch != '\r' && s.ch >= 0 {
+		s.next()
+	}
+	return string(s.src[offs:s.offset])
+}
+
+func (s *Scanner) scanDigits(base int) {
+	for digitVal(s.ch) < base {
+		s.next()
+	}
+}
+
+// scanNumber scans a decimal number such as 42, 1.5, .5 or 1.5E+10.
+func (s *Scanner) scanNumber() (token.Token, string) {
+	offs := s.offset
+	tok := token.INTEGER
+
+	if s.ch != '.' {
+		s.scanDigits(10)
+	}
+	if s.ch == '.' {
+		tok = token.DOUBLE
+		s.next()
+		s.scanDigits(10)
+	}
+	if lower(s.ch) == 'e' {
+		tok = token.DOUBLE
+		s.next()
+		if s.ch == '+' || s.ch == '-' {
+			s.next()
+		}
+		if !isDecimal(s.ch) {
+			s.error(offs, "exponent has no digits")
+		}
+		s.scanDigits(10)
+	}
+
+	return tok, string(s.src[offs:s.offset])
+}
+
+// isRadixPrefix reports whether s.ch, the character following a '&',
+// starts a hexadecimal (&H) or octal (&O) literal.
+func (s *Scanner) isRadixPrefix() bool {
+	switch lower(s.ch) {
+	case 'h':
+		return digitVal(rune(s.peek())) < 16
+	case 'o':
+		return digitVal(rune(s.peek())) < 8
+	}
+	return false
+}
+
+// scanRadixNumber scans the remainder of a &Hxx or &Oxx literal
+// including an optional trailing '&' type suffix.
+func (s *Scanner) scanRadixNumber() string {
+	// '&' already consumed
+	offs := s.offset - 1
+
+	base := 16
+	if lower(s.ch) == 'o' {
+		base = 8
+	}
+	s.next()
+	s.scanDigits(base)
+	if digitVal(s.ch) < 10 {
+		s.errorf(s.offset, "invalid digit %q in number literal", s.ch)
+		s.scanDigits(10)
+	}
+	if s.ch == '&' {
+		s.next()
+	}
+
+	return string(s.src[offs:s.offset])
+}
+
+func (s *Scanner) scanDate() string {
+	// opening # already consumed
+	offs := s.offset - 1
+
+	for {
+		ch := s.ch
+		if ch == '\n' || ch == '\r' || ch < 0 {
+			s.error(offs, "date literal not terminated")
+			break
+		}
+		s.next()
+		if ch == '#' {
+			break
+		}
+	}
+
+	return string(s.src[offs:s.offset])
+}
+
+func (s *Scanner) scanString() string {
+	// opening " already consumed
+	offs := s.offset - 1
+
+	for {
+		ch := s.ch
+		if ch == '\n' || ch == '\r' || ch < 0 {
+			s.error(offs, "string literal not terminated")
+			break
+		}
+		s.next()
+		if ch == '"' {
+			if s.ch != '"' {
+				break
+			}
+			s.next() // "" is an escaped quote
+		}
+	}
+
+	return string(s.src[offs:s.offset])
+}
+
+// isContinuation reports whether s.ch starts a line continuation, that is
+// an underscore followed by optional blanks and the end of the line.
+func (s *Scanner) isContinuation() bool {
+	if s.ch != '_' {
+		return false
+	}
+	for _, b := range s.src[s.rdOffset:] {
+		switch b {
+		case ' ', '\t':
+			continue
+		case '\r', '\n':
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// skipWhitespace skips blanks and line continuations. Line breaks
+// terminate statements and are therefore not skipped, unless they
+// belong to a continuation.
+func (s *Scanner) skipWhitespace() {
+	for {
+		switch {
+		case s.ch == ' ' || s.ch == '\t':
+			s.next()
+		case s.isContinuation():
+			for s.ch == '_' || s.ch == ' ' || s.ch == '\t' {
+				s.next()
+			}
+			if s.ch == '\r' {
+				s.next()
+			}
+			if s.ch == '\n' {
+				s.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Scan scans the next token and returns the token position, the token,
+// and its literal string if applicable. The source end is indicated by
+// token.EOF.
+//
+// Statements are terminated by token.NEWLINE (for "\n", "\r\n" or a lone
+// "\r") and token.COLON. A line ending in " _" is continued on the next
+// line; the continuation is skipped like white space and the positions
+// of the following tokens refer to their actual line.
+//
+// If the returned token is a literal (token.IDENT, token.INTEGER,
+// token.DOUBLE, token.STRING, token.DATE) or a keyword, the literal
+// string has the corresponding value as written in the source, such as
+// &HFF, 1.5E+10, "He said ""hi""" or #10/16/2026#.
+//
+// Identifiers may contain Unicode letters and digits. An escaped
+// identifier such as [my odd name] is returned as token.IDENT with
+// the brackets included in the literal string; it is never a keyword.
+//
+// If the returned token is token.COMMENT, the literal string is the
+// comment text including its marker, for instance "' note" or
+// "Rem note". Rem starts a comment only where a statement may start,
+// that is at the beginning of a line or after a colon. Comments are
+// only returned if the ScanComments mode is set; otherwise they are
+// skipped.
+//
+// If the returned token is token.ILLEGAL, the literal string is the
+// offending character.
+//
+// In all other cases, Scan returns an empty literal string.
+func (s *Scanner) Scan() (pos token.Pos, tok token.Token, lit string) {
+scanAgain:
+	s.skipWhitespace()
+
+	// current token start
+	pos = s.file.Pos(s.offset)
+
+	// determine token value
+	switch ch := s.ch; {
+	case isLetter(ch):
+		lit = s.scanIdentifier()
+		tok = token.Lookup(lit)
+		if tok == token.REM && s.stmtStart {
+			// Rem is only a comment where a statement may start
+			comment := s.scanComment(s.file.Offset(pos))
+			if s.mode&ScanComments == 0 {
+				goto scanAgain
+			}
+			tok = token.COMMENT
+			lit = comment
+		}
+	case isDecimal(ch) || ch == '.' && isDecimal(rune(s.peek())):
+		tok, lit = s.scanNumber()
+	default:
+		s.next() // always make progress
+		switch ch {
+		case eof:
+			tok = token.EOF
+		case '\n':
+			tok = token.NEWLINE
+		case '\r':
+			if s.ch == '\n' {
+				s.next()
+			}
+			tok = token.NEWLINE
+		case '\'':
+			comment := s.scanComment(s.file.Offset(pos))
+			if s.mode&ScanComments == 0 {
+				goto scanAgain
+			}
+			tok = token.COMMENT
+			lit = comment
+		case '"':
+			tok = token.STRING
+			lit = s.scanString()
+		case '#':
+			tok = token.DATE
+			lit = s.scanDate()
+		case '[':
+			tok = token.IDENT
+			lit = s.scanBracketedIdentifier()
+		case '+':
+			tok = token.ADD
+		case '-':
+			tok = token.SUB
+		case '*':
+			tok = token.MUL
+		case '/':
+			tok = token.DIV
+		case '\\':
+			tok = token.IDIV
+		case '^':
+			tok = token.EXP
+		case '&':
+			if s.isRadixPrefix() {
+				tok = token.INTEGER
+				lit = s.scanRadixNumber()
+			} else {
+				tok = token.BITAND
+			}
+		case '=':
+			tok = token.EQ
+		case '<':
+			switch s.ch {
+			case '>':
+				s.next()
+				tok = token.NEQ
+			case '=':
+				s.next()
+				tok = token.LT_ASSIGN
+			default:
+				tok = token.LT
+			}
+		case '>':
+			if s.ch == '=' {
+				s.next()
+				tok = token.GT_ASSIGN
+			} else {
+				tok = token.GT
+			}
+		case ':':
+			tok = token.COLON
+		case ',':
+			tok = token.COMMA
+		case '.':
+			tok = token.DOT
+		case '(':
+			tok = token.LPAREN
+		case ')':
+			tok = token.RPAREN
+		default:
+			// next reports unexpected BOMs - don't repeat
+			if ch != bom {
+				if unicode.IsPrint(ch) {
+					s.errorf(s.offset-utf8.RuneLen(ch), "illegal character %q", ch)
+				} else {
+					s.errorf(s.offset-utf8.RuneLen(ch), "illegal character %#U", ch)
+				}
+			}
+			tok = token.ILLEGAL
+			lit = string(ch)
+		}
+	}
+
+	if tok != token.COMMENT {
+		s.stmtStart = tok == token.NEWLINE || tok == token.COLON
+	}
+	return
+}
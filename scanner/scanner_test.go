@@ -0,0 +1,213 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package scanner_test
+
+import (
+	"testing"
+
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+type elt struct {
+	tok token.Token
+	lit string
+}
+
+func initScanner(s *scanner.Scanner, src string, err scanner.ErrorHandler) *token.File {
+	file := token.NewFileSet().AddFile("test.vbs", -1, len(src))
+	s.Init(file, []byte(src), err, scanner.ScanComments)
+	return file
+}
+
+func scanAll(t *testing.T, src string) []elt {
+	var s scanner.Scanner
+	initScanner(&s, src, func(pos token.Position, msg string) {
+		t.Errorf("%s: %s", pos, msg)
+	})
+	var res []elt
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		res = append(res, elt{tok, lit})
+	}
+	return res
+}
+
+func TestScan(t *testing.T) {
+	testset := []struct {
+		src      string
+		expected []elt
+	}{
+		{`Dim x, y(3)`, []elt{
+			{token.DIM, "Dim"}, {token.IDENT, "x"}, {token.COMMA, ""},
+			{token.IDENT, "y"}, {token.LPAREN, ""}, {token.INTEGER, "3"}, {token.RPAREN, ""},
+		}},
+		{`set obj = CreateObject("Scripting.Dictionary")`, []elt{
+			{token.SET, "set"}, {token.IDENT, "obj"}, {token.EQ, ""}, {token.IDENT, "CreateObject"},
+			{token.LPAREN, ""}, {token.STRING, `"Scripting.Dictionary"`}, {token.RPAREN, ""},
+		}},
+		{`If a <= 1.5 And b <> .5 Then`, []elt{
+			{token.IF, "If"}, {token.IDENT, "a"}, {token.LT_ASSIGN, ""}, {token.DOUBLE, "1.5"},
+			{token.AND, "And"}, {token.IDENT, "b"}, {token.NEQ, ""}, {token.DOUBLE, ".5"}, {token.THEN, "Then"},
+		}},
+		{`x = a Mod 2 ^ 3 \ 4 & "s""q"`, []elt{
+			{token.IDENT, "x"}, {token.EQ, ""}, {token.IDENT, "a"}, {token.MOD, "Mod"}, {token.INTEGER, "2"},
+			{token.EXP, ""}, {token.INTEGER, "3"}, {token.IDIV, ""}, {token.INTEGER, "4"},
+			{token.BITAND, ""}, {token.STRING, `"s""q"`},
+		}},
+		{`Set o = New Foo: o.Run >= Nothing`, []elt{
+			{token.SET, "Set"}, {token.IDENT, "o"}, {token.EQ, ""}, {token.NEW, "New"}, {token.IDENT, "Foo"},
+			{token.COLON, ""}, {token.IDENT, "o"}, {token.DOT, ""}, {token.IDENT, "Run"},
+			{token.GT_ASSIGN, ""}, {token.NOTHING, "Nothing"},
+		}},
+	}
+	for _, tt := range testset {
+		assert.Equal(t, tt.expected, scanAll(t, tt.src), tt.src)
+	}
+}
+
+func TestScanLiterals(t *testing.T) {
+	src := `x = &HFF + &o17 + &HFFFF& & 1.5E+10 & 2e-3 & .5 & "He said ""hi""" & #10/16/2026# &Hz`
+	assert.Equal(t, []elt{
+		{token.IDENT, "x"}, {token.EQ, ""}, {token.INTEGER, "&HFF"}, {token.ADD, ""},
+		{token.INTEGER, "&o17"}, {token.ADD, ""}, {token.INTEGER, "&HFFFF&"}, {token.BITAND, ""},
+		{token.DOUBLE, "1.5E+10"}, {token.BITAND, ""}, {token.DOUBLE, "2e-3"}, {token.BITAND, ""},
+		{token.DOUBLE, ".5"}, {token.BITAND, ""}, {token.STRING, `"He said ""hi"""`}, {token.BITAND, ""},
+		{token.DATE, "#10/16/2026#"}, {token.BITAND, ""}, {token.IDENT, "Hz"},
+	}, scanAll(t, src))
+}
+
+func TestScanIdentifiers(t *testing.T) {
+	src := "Dim [my odd name], [Dim], größe, Ωmega2, 名前\n[] = x [broken"
+	var msgs []string
+	var s scanner.Scanner
+	initScanner(&s, src, func(pos token.Position, msg string) {
+		msgs = append(msgs, pos.String()+": "+msg)
+	})
+	var res []elt
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		res = append(res, elt{tok, lit})
+	}
+	assert.Equal(t, []elt{
+		{token.DIM, "Dim"}, {token.IDENT, "[my odd name]"}, {token.COMMA, ""}, {token.IDENT, "[Dim]"}, {token.COMMA, ""},
+		{token.IDENT, "größe"}, {token.COMMA, ""}, {token.IDENT, "Ωmega2"}, {token.COMMA, ""}, {token.IDENT, "名前"},
+		{token.NEWLINE, ""}, {token.IDENT, "[]"}, {token.EQ, ""}, {token.IDENT, "x"}, {token.IDENT, "[broken"},
+	}, res)
+	assert.Equal(t, []string{"test.vbs:2:8: bracketed identifier not terminated"}, msgs)
+}
+
+func TestScanPos(t *testing.T) {
+	var s scanner.Scanner
+	file := initScanner(&s, "Sub  Foo\r\n  End Sub\n", nil)
+	var got []string
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		got = append(got, file.Position(pos).String())
+	}
+	assert.Equal(t, []string{"test.vbs:1:1", "test.vbs:1:6", "test.vbs:1:9", "test.vbs:2:3", "test.vbs:2:7", "test.vbs:2:10"}, got)
+	assert.Equal(t, 2, file.LineCount())
+}
+
+func TestScanStatementTerminators(t *testing.T) {
+	src := "a = 1 : b = _\n   2\r\nMsgBox a, _  \r\n b\rc"
+	assert.Equal(t, []elt{
+		{token.IDENT, "a"}, {token.EQ, ""}, {token.INTEGER, "1"}, {token.COLON, ""},
+		{token.IDENT, "b"}, {token.EQ, ""}, {token.INTEGER, "2"}, {token.NEWLINE, ""},
+		{token.IDENT, "MsgBox"}, {token.IDENT, "a"}, {token.COMMA, ""}, {token.IDENT, "b"},
+		{token.NEWLINE, ""}, {token.IDENT, "c"},
+	}, scanAll(t, src))
+
+	var s scanner.Scanner
+	file := initScanner(&s, src, nil)
+	var got []string
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.INTEGER || lit == "b" {
+			got = append(got, file.Position(pos).String())
+		}
+	}
+	assert.Equal(t, []string{"test.vbs:1:5", "test.vbs:1:9", "test.vbs:2:4", "test.vbs:4:2"}, got)
+}
+
+func TestScanCRLineEndings(t *testing.T) {
+	var s scanner.Scanner
+	file := initScanner(&s, "a = 1\rb = 2\rc = _\r  3\r", nil)
+	var got []string
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.IDENT || tok == token.INTEGER {
+			got = append(got, file.Position(pos).String())
+		}
+	}
+	assert.Equal(t, []string{"test.vbs:1:1", "test.vbs:1:5", "test.vbs:2:1", "test.vbs:2:5", "test.vbs:3:1", "test.vbs:4:3"}, got)
+	assert.Equal(t, 4, file.LineCount())
+}
+
+func TestScanComments(t *testing.T) {
+	src := "' header\nx = 1 ' trailing\nRem full line\ny = 2 : rem after colon\nremark = Rem\n"
+	assert.Equal(t, []elt{
+		{token.COMMENT, "' header"}, {token.NEWLINE, ""},
+		{token.IDENT, "x"}, {token.EQ, ""}, {token.INTEGER, "1"}, {token.COMMENT, "' trailing"}, {token.NEWLINE, ""},
+		{token.COMMENT, "Rem full line"}, {token.NEWLINE, ""},
+		{token.IDENT, "y"}, {token.EQ, ""}, {token.INTEGER, "2"}, {token.COLON, ""}, {token.COMMENT, "rem after colon"}, {token.NEWLINE, ""},
+		{token.IDENT, "remark"}, {token.EQ, ""}, {token.REM, "Rem"}, {token.NEWLINE, ""},
+	}, scanAll(t, src))
+
+	var s scanner.Scanner
+	file := token.NewFileSet().AddFile("test.vbs", -1, len(src))
+	s.Init(file, []byte(src), nil, 0)
+	var toks []token.Token
+	for {
+		_, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		toks = append(toks, tok)
+	}
+	assert.Equal(t, []token.Token{
+		token.NEWLINE,
+		token.IDENT, token.EQ, token.INTEGER, token.NEWLINE,
+		token.NEWLINE,
+		token.IDENT, token.EQ, token.INTEGER, token.COLON, token.NEWLINE,
+		token.IDENT, token.EQ, token.REM, token.NEWLINE,
+	}, toks)
+}
+
+func TestScanErrors(t *testing.T) {
+	var s scanner.Scanner
+	var msgs []string
+	initScanner(&s, "x = \"abc\ny = $ + #1/1/2000\nz = 1e+", func(pos token.Position, msg string) {
+		msgs = append(msgs, pos.String()+": "+msg)
+	})
+	for {
+		_, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+	}
+	assert.Equal(t, []string{
+		"test.vbs:1:5: string literal not terminated",
+		"test.vbs:2:5: illegal character '$'",
+		"test.vbs:2:9: date literal not terminated",
+		"test.vbs:3:5: exponent has no digits",
+	}, msgs)
+	assert.Equal(t, 4, s.ErrorCount)
+}
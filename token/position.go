@@ -0,0 +1,419 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package token
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// Positions
+
+// Position describes an arbitrary source position
+// including the file, line, and column location.
+// A Position is valid if the line number is > 0.
+type Position struct {
+	Filename string // filename, if any
+	Offset   int    // offset, starting at 0
+	Line     int    // line number, starting at 1
+	Column   int    // column number, starting at 1 (byte count)
+}
+
+// IsValid reports whether the position is valid.
+func (pos *Position) IsValid() bool { return pos.Line > 0 }
+
+// String returns a string in one of several forms:
+//
+//	file:line:column    valid position with file name
+//	file:line           valid position with file name but no column (column == 0)
+//	line:column         valid position without file name
+//	line                valid position without file name and no column (column == 0)
+//	file                invalid position with file name
+//	-                   invalid position without file name
+func (pos Position) String() string {
+	s := pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprint(pos.Line)
+		if pos.Column != 0 {
+			s += fmt.Sprintf(":%d", pos.Column)
+		}
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// -----------------------------------------------------------------------------
+// File
+
+// A File is a handle for a file belonging to a FileSet.
+// A File has a name, size, and line offset table.
+type File struct {
+	name string // file name as provided to AddFile
+	base int    // Pos value range for this file is [base...base+size]
+	size int    // file size as provided to AddFile
+
+	// lines and origin are protected by mutex
+	mutex  sync.Mutex
+	lines  []int      // lines contains the offset of the first character for each line (the first entry is always 0)
+	origin *OffsetMap // maps offsets back to the undecoded source; or nil
+}
+
+// Name returns the file name of file f as registered with AddFile.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the base offset of file f as registered with AddFile.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the size of file f as registered with AddFile.
+func (f *File) Size() int {
+	return f.size
+}
+
+// LineCount returns the number of lines in file f.
+func (f *File) LineCount() int {
+	f.mutex.Lock()
+	n := len(f.lines)
+	f.mutex.Unlock()
+	return n
+}
+
+// AddLine adds the line offset for a new line.
+// The line offset must be larger than the offset for the previous line
+// and smaller than the file size; otherwise the line offset is ignored.
+func (f *File) AddLine(offset int) {
+	f.mutex.Lock()
+	if i := len(f.lines); (i == 0 || f.lines[i-1] < offset) && offset < f.size {
+		f.lines = append(f.lines, offset)
+	}
+	f.mutex.Unlock()
+}
+
+// SetLines sets the line offsets for a file and reports whether it succeeded.
+// The line offsets are the offsets of the first character of each line;
+// for instance for the content "ab\nc\n" the line offsets are {0, 3}.
+// An empty file has an empty line offset table.
+// Each line offset must be larger than the offset for the previous line
+// and smaller than the file size; otherwise SetLines fails and returns
+// false.
+func (f *File) SetLines(lines []int) bool {
+	// verify validity of lines table
+	size := f.size
+	for i, offset := range lines {
+		if i > 0 && offset <= lines[i-1] || size <= offset {
+			return false
+		}
+	}
+
+	// set lines table
+	f.mutex.Lock()
+	f.lines = lines
+	f.mutex.Unlock()
+	return true
+}
+
+// SetLinesForContent sets the line offsets for the given file content.
+// A line ends with "\r\n", "\n" or a lone "\r".
+func (f *File) SetLinesForContent(content []byte) {
+	var lines []int
+	line := 0
+	for offset, b := range content {
+		if line >= 0 {
+			lines = append(lines, line)
+		}
+		line = -1
+		if b == '\n' || b == '\r' && (offset+1 == len(content) || content[offset+1] != '\n') {
+			line = offset + 1
+		}
+	}
+
+	// set lines table
+	f.mutex.Lock()
+	f.lines = lines
+	f.mutex.Unlock()
+}
+
+// SetOffsetMap records that the content of f was decoded from a source
+// in another encoding, for instance UTF-16. The offsets reported in the
+// Position values of f are then mapped back to byte offsets in that
+// source, while lines and columns keep referring to the decoded content,
+// counted from the start recorded with OffsetMap.SetStart.
+func (f *File) SetOffsetMap(m *OffsetMap) {
+	f.mutex.Lock()
+	f.origin = m
+	f.mutex.Unlock()
+}
+
+// Pos returns the Pos value for the given file offset;
+// the offset must be <= f.Size().
+// f.Pos(f.Offset(p)) == p.
+func (f *File) Pos(offset int) Pos {
+	if offset > f.size {
+		panic(fmt.Sprintf("invalid file offset %d (should be <= %d)", offset, f.size))
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the offset for the given file position p;
+// p must be a valid Pos value in that file.
+// f.Offset(f.Pos(offset)) == offset.
+func (f *File) Offset(p Pos) int {
+	if int(p) < f.base || int(p) > f.base+f.size {
+		panic(fmt.Sprintf("invalid Pos value %d (should be in [%d, %d])", p, f.base, f.base+f.size))
+	}
+	return int(p) - f.base
+}
+
+// Line returns the line number for the given file position p;
+// p must be a Pos value in that file or NoPos.
+func (f *File) Line(p Pos) int {
+	return f.Position(p).Line
+}
+
+// Position returns the Position value for the given file position p;
+// p must be a Pos value in that file or NoPos.
+func (f *File) Position(p Pos) (pos Position) {
+	if p.IsValid() {
+		if int(p) < f.base || int(p) > f.base+f.size {
+			panic(fmt.Sprintf("invalid Pos value %d (should be in [%d, %d])", p, f.base, f.base+f.size))
+		}
+		pos = f.position(int(p) - f.base)
+	}
+	return
+}
+
+func (f *File) position(offset int) (pos Position) {
+	pos.Filename = f.name
+	pos.Offset = offset
+	f.mutex.Lock()
+	if i := searchInts(f.lines, offset); i >= 0 {
+		pos.Line, pos.Column = i+1, offset-f.lines[i]+1
+	}
+	if m := f.origin; m != nil {
+		pos.Offset = m.Origin(offset)
+		if m.line > 0 && pos.Line > 0 {
+			if pos.Line == 1 {
+				pos.Column += m.column - 1
+			}
+			pos.Line += m.line - 1
+		}
+	}
+	f.mutex.Unlock()
+	return
+}
+
+func searchInts(a []int, x int) int {
+	return sort.Search(len(a), func(i int) bool { return a[i] > x }) - 1
+}
+
+// -----------------------------------------------------------------------------
+// OffsetMap
+
+// An OffsetMap maps the offsets of decoded source text back to the byte
+// offsets of the original source it was decoded from. It is built by
+// calling Add for each decoded character in order.
+//
+// The decoded text may be a piece of the original source, such as a
+// script embedded in a document; SetStart then records where it starts.
+type OffsetMap struct {
+	runs   []offsetRun
+	size   int // decoded size so far
+	origin int // original offset reached so far
+
+	// line and column of the start of the decoded text, or 0
+	line, column int
+}
+
+// An offsetRun describes a sequence of characters that all have the same
+// decoded and original width.
+type offsetRun struct {
+	offset      int // decoded offset of the first character
+	origin      int // original offset of the first character
+	width       int // decoded width of each character
+	originWidth int // original width of each character
+}
+
+// SetStart records that the decoded text starts at the byte offset
+// origin of the original source, at the given line and column. The
+// Position values of a File using m are then relative to that start.
+// SetStart must be called before Add.
+func (m *OffsetMap) SetStart(origin, line, column int) {
+	m.origin, m.line, m.column = origin, line, column
+}
+
+// Add appends a character that is width bytes long in the decoded text
+// and was decoded from originWidth bytes of the original source.
+func (m *OffsetMap) Add(width, originWidth int) {
+	if n := len(m.runs); n == 0 || m.runs[n-1].width != width || m.runs[n-1].originWidth != originWidth {
+		m.runs = append(m.runs, offsetRun{m.size, m.origin, width, originWidth})
+	}
+	m.size += width
+	m.origin += originWidth
+}
+
+// Origin returns the original byte offset of the decoded offset.
+// Offsets at or past the end of the decoded text map to the end of
+// the original source.
+func (m *OffsetMap) Origin(offset int) int {
+	if offset >= m.size {
+		return m.origin + offset - m.size
+	}
+	i := sort.Search(len(m.runs), func(i int) bool { return m.runs[i].offset > offset }) - 1
+	if i < 0 {
+		return offset
+	}
+	r := m.runs[i]
+	return r.origin + (offset-r.offset)/r.width*r.originWidth
+}
+
+// -----------------------------------------------------------------------------
+// FileSet
+
+// A FileSet represents a set of source files.
+// Methods of file sets are synchronized; multiple goroutines
+// may invoke them concurrently.
+//
+// The byte offsets for each file in a file set are mapped into
+// distinct (integer) intervals, one interval [base, base+size]
+// per file. Base represents the first byte in the file, and size
+// is the corresponding file size. A Pos value is a value in such
+// an interval. By determining the interval a Pos value belongs
+// to, the file, its file base, and thus the byte offset (position)
+// the Pos value is representing can be computed.
+//
+// When adding a new file, a file base must be provided. That can
+// be any integer value that is past the end of any interval of any
+// file already in the file set. For convenience, FileSet.Base provides
+// such a value, which is simply the end of the Pos interval of the most
+// recently added file, plus one. Unless there is a need to extend an
+// interval later, using the FileSet.Base should be used as argument
+// for FileSet.AddFile.
+type FileSet struct {
+	mutex sync.RWMutex // protects the file set
+	base  int          // base offset for the next file
+	files []*File      // list of files in the order added to the set
+	last  *File        // cache of last file looked up
+}
+
+// NewFileSet creates a new file set.
+func NewFileSet() *FileSet {
+	return &FileSet{
+		base: 1, // 0 == NoPos
+	}
+}
+
+// Base returns the minimum base offset that must be provided to
+// AddFile when adding the next file.
+func (s *FileSet) Base() int {
+	s.mutex.RLock()
+	b := s.base
+	s.mutex.RUnlock()
+	return b
+}
+
+// AddFile adds a new file with a given filename, base offset, and file size
+// to the file set s and returns the file. Multiple files may have the same
+// name. The base offset must not be smaller than the FileSet's Base(), and
+// size must not be negative. As a special case, if a negative base is provided,
+// the current value of the FileSet's Base() is used instead.
+//
+// Adding the file will set the file set's Base() value to base + size + 1
+// as the minimum base value for the next file.
+func (s *FileSet) AddFile(filename string, base, size int) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if base < 0 {
+		base = s.base
+	}
+	if base < s.base {
+		panic(fmt.Sprintf("invalid base %d (should be >= %d)", base, s.base))
+	}
+	if size < 0 {
+		panic(fmt.Sprintf("invalid size %d (should be >= 0)", size))
+	}
+	f := &File{name: filename, base: base, size: size, lines: []int{0}}
+	// base >= s.base && size >= 0
+	base += size + 1 // +1 because EOF also has a position
+	if base < 0 {
+		panic("token.Pos offset overflow (> 2G of source code in file set)")
+	}
+	// add the file to the file set
+	s.base = base
+	s.files = append(s.files, f)
+	s.last = f
+	return f
+}
+
+// Iterate calls f for the files in the file set in the order they were added
+// until f returns false.
+func (s *FileSet) Iterate(f func(*File) bool) {
+	for i := 0; ; i++ {
+		var file *File
+		s.mutex.RLock()
+		if i < len(s.files) {
+			file = s.files[i]
+		}
+		s.mutex.RUnlock()
+		if file == nil || !f(file) {
+			break
+		}
+	}
+}
+
+func searchFiles(a []*File, x int) int {
+	return sort.Search(len(a), func(i int) bool { return a[i].base > x }) - 1
+}
+
+func (s *FileSet) file(p Pos) *File {
+	s.mutex.RLock()
+	// common case: p is in last file
+	if f := s.last; f != nil && f.base <= int(p) && int(p) <= f.base+f.size {
+		s.mutex.RUnlock()
+		return f
+	}
+	// p is not in last file - search all files
+	if i := searchFiles(s.files, int(p)); i >= 0 {
+		f := s.files[i]
+		// f.base <= int(p) by definition of searchFiles
+		if int(p) <= f.base+f.size {
+			s.mutex.RUnlock()
+			s.mutex.Lock()
+			s.last = f // race is ok - s.last is only a cache
+			s.mutex.Unlock()
+			return f
+		}
+	}
+	s.mutex.RUnlock()
+	return nil
+}
+
+// File returns the file that contains the position p.
+// If no such file is found (for instance for p == NoPos or DynPos),
+// the result is nil.
+func (s *FileSet) File(p Pos) (f *File) {
+	if p.IsValid() && p != DynPos {
+		f = s.file(p)
+	}
+	return
+}
+
+// Position converts a Pos p in the fileset into a Position value.
+// Positions that do not belong to any file of the set, such as NoPos
+// and DynPos, yield the zero Position.
+func (s *FileSet) Position(p Pos) (pos Position) {
+	if f := s.File(p); f != nil {
+		return f.position(int(p) - f.base)
+	}
+	return
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package token_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSet(t *testing.T) {
+	fset := token.NewFileSet()
+
+	src1 := "Dim x\nx = 1\n"
+	f1 := fset.AddFile("a.vbs", -1, len(src1))
+	f1.SetLinesForContent([]byte(src1))
+
+	src2 := "MsgBox 1\r\n\r\nWScript.Quit"
+	f2 := fset.AddFile("deploy.vbs", -1, len(src2))
+	f2.AddLine(10)
+	f2.AddLine(12)
+
+	assert.Equal(t, 3, f2.LineCount())
+	assert.Equal(t, f2, fset.File(f2.Pos(3)))
+	assert.Equal(t, token.Position{Filename: "a.vbs", Offset: 8, Line: 2, Column: 3}, fset.Position(f1.Pos(8)))
+	assert.Equal(t, "deploy.vbs:3:9", fset.Position(f2.Pos(20)).String())
+	assert.Equal(t, 12, f2.Offset(f2.Pos(12)))
+	assert.Equal(t, 3, f2.Line(f2.Pos(12)))
+
+	assert.Nil(t, fset.File(token.NoPos))
+	assert.Nil(t, fset.File(token.DynPos))
+	assert.Equal(t, token.Position{}, fset.Position(token.DynPos))
+	assert.Equal(t, "-", fset.Position(token.NoPos).String())
+}
+
+func TestSetLinesForContent(t *testing.T) {
+	src := "a\r\nb\rc\nd\r"
+	f := token.NewFileSet().AddFile("a.vbs", -1, len(src))
+	f.SetLinesForContent([]byte(src))
+	assert.Equal(t, 4, f.LineCount())
+	assert.Equal(t, "a.vbs:3:1", f.Position(f.Pos(strings.Index(src, "c"))).String())
+	assert.Equal(t, "a.vbs:4:1", f.Position(f.Pos(strings.Index(src, "d"))).String())
+}
+
+func TestOffsetMapStart(t *testing.T) {
+	// "x = 1\ny" taken from offset 40 of a document, at line 3, column 9
+	code := "x = 1\ny"
+	var m token.OffsetMap
+	m.SetStart(40, 3, 9)
+	for range code {
+		m.Add(1, 1)
+	}
+	f := token.NewFileSet().AddFile("a.hta", -1, len(code))
+	f.SetLinesForContent([]byte(code))
+	f.SetOffsetMap(&m)
+
+	assert.Equal(t, token.Position{Filename: "a.hta", Offset: 44, Line: 3, Column: 13}, f.Position(f.Pos(4)))
+	assert.Equal(t, token.Position{Filename: "a.hta", Offset: 46, Line: 4, Column: 1}, f.Position(f.Pos(6)))
+	assert.Equal(t, token.Position{Filename: "a.hta", Offset: 47, Line: 4, Column: 2}, f.Position(f.Pos(7)))
+}
@@ -3,6 +3,11 @@
 // license that can be found in the LICENSE file.
 package token
 
+import (
+	"strconv"
+	"strings"
+)
+
 type Pos int
 
 // IsValid reports whether the position is valid.
@@ -18,102 +23,371 @@ const NoPos Pos = 0
 
 const DynPos Pos = -1
 
-type Token string
+// Token is the set of lexical tokens of VBScript.
+type Token int
+
+// The list of tokens.
+const (
+	// Special tokens
+	ILLEGAL Token = iota
+	EOF
+	NEWLINE
+	COMMENT
+
+	literal_beg
+	IDENT // main
+
+	// Data Types, the kinds of literals
+	BOOLEAN
+	BYTE
+	INTEGER
+	CURRENCY
+	LONG
+	SINGLE
+	DOUBLE
+	DATE
+	STRING
+	OBJECT
+	ERROR
+	literal_end
+
+	operator_beg
+	ADD  // +
+	SUB  // -
+	MUL  // *
+	DIV  // /
+	IDIV // \
+	MOD  // Mod
+	EXP  // ^
+
+	IS // Is
+
+	BITAND // &
+
+	NOT // Not
+	AND // And
+	OR  // Or
+	XOR // Xor
+	EQV // Eqv
+	IMP // Imp
+
+	EQ  // =
+	NEQ // <>
+
+	LT // <
+	GT // >
+
+	LT_ASSIGN // <=
+	GT_ASSIGN // >=
+
+	COLON  // :
+	COMMA  // ,
+	DOT    // .
+	LPAREN // (
+	RPAREN // )
+
+	APOSTROPHE // '
+	operator_end
+
+	keyword_beg
+	FALSE
+	TRUE
+	NOTHING
+	EMPTY
+	NULL
+
+	BYVAL
+	BYREF
+
+	GET
+	LET
+	SET
+	CONST
+
+	DIM
+	REDIM
+	PRESERVE
+	FOR
+	EACH
+	IN
+	TO
+	STEP
+	NEXT
+	EXIT
+	SELECT
+	CASE
+	THEN
+	IF
+	ELSEIF
+	ELSE
+	WITH
+	WHILE
+	WEND
+	END
+	SUB_LIT
+	PROPERTY
+	FUNCTION
+	CLASS
+	PUBLIC
+	PRIVATE
+	CALL
+	ON
+	GOTO
+	RESUME
+	STOP
+	RANDOMIZE
+	OPTION
+	EXPLICIT
+	DO
+	LOOP
+	UNTIL
+	NEW
+	ERASE
+	EXECUTE
+	EXECUTEGLOBAL
+	REM
+	keyword_end
+)
+
+var tokens = [...]string{
+	ILLEGAL: "ILLEGAL",
+	EOF:     "EOF",
+	NEWLINE: "NEWLINE",
+	COMMENT: "COMMENT",
+
+	IDENT: "IDENT",
+
+	BOOLEAN:  "Boolean",
+	BYTE:     "Byte",
+	INTEGER:  "Integer",
+	CURRENCY: "Currency",
+	LONG:     "Long",
+	SINGLE:   "Single",
+	DOUBLE:   "Double",
+	DATE:     "Date",
+	STRING:   "String",
+	OBJECT:   "Object",
+	ERROR:    "Error",
+
+	ADD:  "+",
+	SUB:  "-",
+	MUL:  "*",
+	DIV:  "/",
+	IDIV: "\\",
+	MOD:  "Mod",
+	EXP:  "^",
+
+	IS: "Is",
+
+	BITAND: "&",
+
+	NOT: "Not",
+	AND: "And",
+	OR:  "Or",
+	XOR: "Xor",
+	EQV: "Eqv",
+	IMP: "Imp",
 
+	EQ:  "=",
+	NEQ: "<>",
+
+	LT: "<",
+	GT: ">",
+
+	LT_ASSIGN: "<=",
+	GT_ASSIGN: ">=",
+
+	COLON:  ":",
+	COMMA:  ",",
+	DOT:    ".",
+	LPAREN: "(",
+	RPAREN: ")",
+
+	APOSTROPHE: "'",
+
+	FALSE:   "False",
+	TRUE:    "True",
+	NOTHING: "Nothing",
+	EMPTY:   "Empty",
+	NULL:    "Null",
+
+	BYVAL: "ByVal",
+	BYREF: "ByRef",
+
+	GET:   "Get",
+	LET:   "Let",
+	SET:   "Set",
+	CONST: "Const",
+
+	DIM:           "Dim",
+	REDIM:         "ReDim",
+	PRESERVE:      "Preserve",
+	FOR:           "For",
+	EACH:          "Each",
+	IN:            "In",
+	TO:            "To",
+	STEP:          "Step",
+	NEXT:          "Next",
+	EXIT:          "Exit",
+	SELECT:        "Select",
+	CASE:          "Case",
+	THEN:          "Then",
+	IF:            "If",
+	ELSEIF:        "ElseIf",
+	ELSE:          "Else",
+	WITH:          "With",
+	WHILE:         "While",
+	WEND:          "Wend",
+	END:           "End",
+	SUB_LIT:       "Sub",
+	PROPERTY:      "Property",
+	FUNCTION:      "Function",
+	CLASS:         "Class",
+	PUBLIC:        "Public",
+	PRIVATE:       "Private",
+	CALL:          "Call",
+	ON:            "On",
+	GOTO:          "GoTo",
+	RESUME:        "Resume",
+	STOP:          "Stop",
+	RANDOMIZE:     "Randomize",
+	OPTION:        "Option",
+	EXPLICIT:      "Explicit",
+	DO:            "Do",
+	LOOP:          "Loop",
+	UNTIL:         "Until",
+	NEW:           "New",
+	ERASE:         "Erase",
+	EXECUTE:       "Execute",
+	EXECUTEGLOBAL: "ExecuteGlobal",
+	REM:           "Rem",
+}
+
+// String returns the string corresponding to the token tok.
+// For operators, delimiters, and keywords the string is the actual
+// token character sequence in its canonical spelling (e.g., for the
+// token ELSEIF, the string is "ElseIf"). For the data types the string
+// is the type name (e.g., for the token STRING, the string is "String").
+// For all other tokens the string corresponds to the token constant
+// name (e.g. for the token IDENT, the string is "IDENT").
+func (tok Token) String() string {
+	s := ""
+	if 0 <= tok && tok < Token(len(tokens)) {
+		s = tokens[tok]
+	}
+	if s == "" {
+		s = "token(" + strconv.Itoa(int(tok)) + ")"
+	}
+	return s
+}
+
+// A set of constants for precedence-based expression parsing.
+// Non-operators have lowest precedence, followed by operators
+// starting with precedence 1 up to unary operators. The highest
+// precedence serves as "catch-all" precedence for selector,
+// indexing, and other operator and delimiter tokens.
+//
+// VBScript evaluates, from loosest to tightest binding: Imp, Eqv,
+// Xor, Or, And, Not, the comparisons (=, <>, <, >, <=, >=, Is), &,
+// + and -, Mod, \, * and /, unary negation, and ^.
 const (
-	ADD  = "+"
-	SUB  = "-"
-	MUL  = "*"
-	DIV  = "/"
-	IDIV = "\\"
-	MOD  = "Mod"
-	EXP  = "^"
-
-	IS = "Is"
-
-	BITAND = "&"
-
-	NOT = "Not"
-	AND = "And"
-	OR  = "Or"
-	XOR = "Xor"
-	EQV = "Eqv"
-	IMP = "Imp"
-
-	EQ  = "="
-	NEQ = "<>"
-
-	LT = "<"
-	GT = ">"
-
-	COLON = ":"
-	COMMA = ","
-
-	LT_ASSIGN = "<="
-	GT_ASSIGN = ">="
-
-	APOSTROPHE = "'"
-
-	FALSE   = "False"
-	TRUE    = "True"
-	NOTHING = "Nothing"
-
-	BYVAL = "ByVal"
-	BYREF = "ByRef"
-
-	GET   = "Get"
-	LET   = "Let"
-	SET   = "Set"
-	CONST = "Const"
-
-	// Data Types
-
-	EMPTY    = "Empty"
-	NULL     = "Null"
-	BOOLEAN  = "Boolean"
-	BYTE     = "Byte"
-	INTEGER  = "Integer"
-	CURRENCY = "Currency"
-	LONG     = "Long"
-	SINGLE   = "Single"
-	DOUBLE   = "Double"
-	DATE     = "Date"
-	STRING   = "String"
-	OBJECT   = "Object"
-	ERROR    = "Error"
-
-	DIM       = "Dim"
-	REDIM     = "ReDim"
-	PRESERVE  = "Preserve"
-	FOR       = "For"
-	EACH      = "Each"
-	IN        = "In"
-	TO        = "To"
-	STEP      = "Step"
-	NEXT      = "Next"
-	EXIT      = "Exit"
-	SELECT    = "Select"
-	CASE      = "Case"
-	THEN      = "Then"
-	IF        = "If"
-	ELSEIF    = "ElseIf"
-	ELSE      = "Else"
-	WITH      = "With"
-	WHILE     = "While"
-	WEND      = "Wend"
-	END       = "End"
-	SUB_LIT   = "Sub"
-	PROPERTY  = "Property"
-	FUNCTION  = "Function"
-	CLASS     = "Class"
-	PUBLIC    = "Public"
-	PRIVATE   = "Private"
-	CALL      = "Call"
-	ON        = "On"
-	GOTO      = "GoTo"
-	RESUME    = "Resume"
-	STOP      = "Stop"
-	RANDOMIZE = "Randomize"
-	OPTION    = "Option"
-	EXPLICIT  = "Explicit"
+	LowestPrec  = 0 // non-operators
+	NotPrec     = 6 // logical negation
+	UnaryPrec   = 13
+	HighestPrec = 14
 )
+
+// Precedence returns the operator precedence of the binary
+// operator op. If op is not a binary operator, the result
+// is LowestPrec. As Not is only used as a unary operator,
+// its precedence is NotPrec; unary negation has UnaryPrec.
+func (op Token) Precedence() int {
+	switch op {
+	case IMP:
+		return 1
+	case EQV:
+		return 2
+	case XOR:
+		return 3
+	case OR:
+		return 4
+	case AND:
+		return 5
+	case NOT:
+		return NotPrec
+	case EQ, NEQ, LT, GT, LT_ASSIGN, GT_ASSIGN, IS:
+		return 7
+	case BITAND:
+		return 8
+	case ADD, SUB:
+		return 9
+	case MOD:
+		return 10
+	case IDIV:
+		return 11
+	case MUL, DIV:
+		return 12
+	case EXP:
+		return HighestPrec
+	}
+	return LowestPrec
+}
+
+var keywords map[string]Token
+
+func init() {
+	ops := []Token{MOD, IS, NOT, AND, OR, XOR, EQV, IMP} // word operators
+	keywords = make(map[string]Token, int(keyword_end-(keyword_beg+1))+len(ops))
+	for i := keyword_beg + 1; i < keyword_end; i++ {
+		keywords[strings.ToLower(tokens[i])] = i
+	}
+	for _, tok := range ops {
+		keywords[strings.ToLower(tokens[tok])] = tok
+	}
+}
+
+// Lookup maps an identifier to its keyword token or IDENT (if not a keyword).
+// VBScript keywords are case-insensitive, so "dim", "DIM" and "Dim" all
+// map to DIM. The word operators (Mod, Is, Not, And, ...) are keywords too.
+//
+// The data type names (Integer, String, Date, ...) are not keywords; they
+// only serve as the kinds of literals.
+func Lookup(ident string) Token {
+	if tok, ok := keywords[strings.ToLower(ident)]; ok {
+		return tok
+	}
+	return IDENT
+}
+
+// Predicates
+
+// IsLiteral returns true for tokens corresponding to identifiers
+// and basic type literals; it returns false otherwise.
+func (tok Token) IsLiteral() bool { return literal_beg < tok && tok < literal_end }
+
+// IsOperator returns true for tokens corresponding to operators and
+// delimiters, including the word operators such as Mod and And; it
+// returns false otherwise.
+func (tok Token) IsOperator() bool { return operator_beg < tok && tok < operator_end }
+
+// IsKeyword returns true for tokens corresponding to keywords;
+// it returns false otherwise. The word operators are operators,
+// not keywords, even though Lookup recognizes them.
+func (tok Token) IsKeyword() bool { return keyword_beg < tok && tok < keyword_end }
+
+// IsKeyword reports whether name is a VBScript keyword, such as "Dim"
+// or "elseif", regardless of its casing.
+func IsKeyword(name string) bool {
+	_, ok := keywords[strings.ToLower(name)]
+	return ok
+}
+
+// Canonical returns the canonical spelling of the keyword name, for
+// instance "ElseIf" for "ELSEIF". If name is not a keyword, it is
+// returned unchanged.
+func Canonical(name string) string {
+	if tok, ok := keywords[strings.ToLower(name)]; ok {
+		return tok.String()
+	}
+	return name
+}
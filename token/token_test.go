@@ -0,0 +1,88 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package token_test
+
+import (
+	"testing"
+
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"dim", "DIM", "Dim", "dIm"} {
+		assert.Equal(t, token.DIM, token.Lookup(name), name)
+	}
+	assert.Equal(t, token.ELSEIF, token.Lookup("ELSEIF"))
+	assert.Equal(t, token.SUB_LIT, token.Lookup("sub"))
+	assert.Equal(t, token.IDENT, token.Lookup("MsgBox"))
+	assert.Equal(t, token.IDENT, token.Lookup("String"))
+}
+
+func TestIsKeyword(t *testing.T) {
+	assert.True(t, token.IsKeyword("with"))
+	assert.True(t, token.IsKeyword("ExecuteGlobal"))
+	assert.False(t, token.IsKeyword("WScript"))
+	assert.False(t, token.IsKeyword(""))
+}
+
+func TestCanonical(t *testing.T) {
+	assert.Equal(t, "ElseIf", token.Canonical("ELSEIF"))
+	assert.Equal(t, "GoTo", token.Canonical("goto"))
+	assert.Equal(t, "WITH_", token.Canonical("WITH_"))
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "ElseIf", token.ELSEIF.String())
+	assert.Equal(t, "<=", token.LT_ASSIGN.String())
+	assert.Equal(t, "String", token.STRING.String())
+	assert.Equal(t, "IDENT", token.IDENT.String())
+	assert.Equal(t, "token(-1)", token.Token(-1).String())
+}
+
+func TestPrecedence(t *testing.T) {
+	ops := [][]token.Token{
+		{token.IMP},
+		{token.EQV},
+		{token.XOR},
+		{token.OR},
+		{token.AND},
+		{token.NOT},
+		{token.EQ, token.NEQ, token.LT, token.GT, token.LT_ASSIGN, token.GT_ASSIGN, token.IS},
+		{token.BITAND},
+		{token.ADD, token.SUB},
+		{token.MOD},
+		{token.IDIV},
+		{token.MUL, token.DIV},
+	}
+	prev := token.LowestPrec
+	for _, level := range ops {
+		for _, op := range level {
+			assert.Greater(t, op.Precedence(), prev, op.String())
+			assert.Equal(t, level[0].Precedence(), op.Precedence(), op.String())
+		}
+		prev = level[0].Precedence()
+	}
+	assert.Less(t, prev, token.UnaryPrec)
+	assert.Greater(t, token.EXP.Precedence(), token.UnaryPrec)
+	assert.Equal(t, token.LowestPrec, token.IDENT.Precedence())
+	assert.Equal(t, token.LowestPrec, token.COMMA.Precedence())
+}
+
+func TestPredicates(t *testing.T) {
+	assert.True(t, token.IDENT.IsLiteral())
+	assert.True(t, token.DATE.IsLiteral())
+	assert.False(t, token.DIM.IsLiteral())
+
+	assert.True(t, token.ADD.IsOperator())
+	assert.True(t, token.MOD.IsOperator())
+	assert.True(t, token.RPAREN.IsOperator())
+	assert.False(t, token.NEWLINE.IsOperator())
+	assert.False(t, token.EXIT.IsOperator())
+
+	assert.True(t, token.EXECUTEGLOBAL.IsKeyword())
+	assert.True(t, token.NOTHING.IsKeyword())
+	assert.False(t, token.AND.IsKeyword())
+	assert.False(t, token.STRING.IsKeyword())
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package vbsconv implements conversions between VBScript literals
+// and Go values, in the manner of strconv.
+//
+// String literals are stored unquoted in ast.BasicLit.Value; all other
+// literals keep their source spelling, e.g. &HFF, 1.5E+10 or #10/16/2026#.
+package vbsconv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSyntax indicates that a literal does not have the right syntax.
+var ErrSyntax = errors.New("invalid syntax")
+
+// ErrRange indicates that a literal is out of range for its type.
+var ErrRange = errors.New("value out of range")
+
+// A LitError records a failed conversion.
+type LitError struct {
+	Func string // the failing function (ParseInteger, ParseDate, ...)
+	Lit  string // the input
+	Err  error  // the reason the conversion failed (e.g. ErrRange, ErrSyntax, etc.)
+}
+
+func (e *LitError) Error() string {
+	return "vbsconv." + e.Func + ": parsing " + strconv.Quote(e.Lit) + ": " + e.Err.Error()
+}
+
+func (e *LitError) Unwrap() error { return e.Err }
+
+// Quote returns a VBScript string literal representing s. Embedded
+// double quotes are doubled.
+func Quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Unquote interprets s as a VBScript string literal, returning the
+// string value that s quotes.
+func Unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", ErrSyntax
+	}
+	s = s[1 : len(s)-1]
+	if !strings.Contains(s, `"`) {
+		return s, nil
+	}
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			if i+1 == len(s) || s[i+1] != '"' {
+				return "", ErrSyntax
+			}
+			i++
+		}
+		buf.WriteByte(s[i])
+	}
+	return buf.String(), nil
+}
+
+// ParseInteger interprets s as a VBScript integer literal: a decimal
+// number, a hexadecimal &H or an octal &O literal, the latter optionally
+// followed by the Long type suffix '&'.
+//
+// As in VBScript, hexadecimal and octal literals that fit into 16 bits
+// are Integers and those that fit into 32 bits are Longs, so &HFFFF is
+// -1 while &HFFFF& is 65535.
+func ParseInteger(s string) (int64, error) {
+	const fnParseInteger = "ParseInteger"
+
+	if len(s) < 3 || s[0] != '&' {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, &LitError{fnParseInteger, s, numError(err)}
+		}
+		return i, nil
+	}
+
+	base := 0
+	switch s[1] {
+	case 'h', 'H':
+		base = 16
+	case 'o', 'O':
+		base = 8
+	default:
+		return 0, &LitError{fnParseInteger, s, ErrSyntax}
+	}
+	digits, long := s[2:], false
+	if strings.HasSuffix(digits, "&") {
+		digits, long = digits[:len(digits)-1], true
+	}
+	u, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return 0, &LitError{fnParseInteger, s, numError(err)}
+	}
+	if u <= 0xFFFF && !long {
+		return int64(int16(u)), nil
+	}
+	return int64(int32(u)), nil
+}
+
+// FormatInteger returns the VBScript literal for i in the given base,
+// which must be 8, 10 or 16. Octal and hexadecimal literals carry the
+// Long type suffix when i does not fit into an Integer.
+func FormatInteger(i int64, base int) string {
+	var prefix string
+	switch base {
+	case 10:
+		return strconv.FormatInt(i, 10)
+	case 8:
+		prefix = "&O"
+	case 16:
+		prefix = "&H"
+	default:
+		panic("vbsconv: illegal FormatInteger base")
+	}
+	if i >= -0x8000 && i <= 0x7FFF {
+		return prefix + strings.ToUpper(strconv.FormatUint(uint64(uint16(i)), base))
+	}
+	return prefix + strings.ToUpper(strconv.FormatUint(uint64(uint32(i)), base)) + "&"
+}
+
+// ParseFloat interprets s as a VBScript floating-point literal such as
+// 1.5, .5 or 1.5E+10.
+func ParseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, &LitError{"ParseFloat", s, numError(err)}
+	}
+	return f, nil
+}
+
+func numError(err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrRange
+	}
+	return ErrSyntax
+}
+
+// zeroDate is the VBScript zero date. A date literal holding only a
+// time of day refers to this date.
+var zeroDate = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+var (
+	dateLayouts = []string{
+		"1/2/2006",
+		"1-2-2006",
+		"2006-1-2",
+		"2006/1/2",
+		"January 2, 2006",
+		"Jan 2, 2006",
+		"January 2 2006",
+		"Jan 2 2006",
+		"2 January 2006",
+		"2 Jan 2006",
+	}
+	timeLayouts = []string{
+		"15:04:05",
+		"15:04",
+		"3:04:05 PM",
+		"3:04:05PM",
+		"3:04 PM",
+		"3:04PM",
+		"3 PM",
+		"3PM",
+	}
+)
+
+// ParseDate interprets s as a VBScript date literal, for instance
+// #10/16/2026#, #2026-10-16 14:30# or #2:30:00 PM#. The enclosing '#'
+// characters are optional. Dates are interpreted in the US (month/day)
+// order VBScript uses for literals; a literal without a date refers to
+// the VBScript zero date, December 30, 1899.
+func ParseDate(s string) (time.Time, error) {
+	const fnParseDate = "ParseDate"
+
+	v := s
+	if strings.HasPrefix(v, "#") {
+		if len(v) < 2 || !strings.HasSuffix(v, "#") {
+			return time.Time{}, &LitError{fnParseDate, s, ErrSyntax}
+		}
+		v = v[1 : len(v)-1]
+	}
+	v = strings.Join(strings.Fields(strings.ToUpper(v)), " ")
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+		for _, tl := range timeLayouts {
+			if t, err := time.Parse(layout+" "+tl, v); err == nil {
+				return t, nil
+			}
+		}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return zeroDate.Add(time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second), nil
+		}
+	}
+	return time.Time{}, &LitError{fnParseDate, s, ErrSyntax}
+}
+
+// FormatDate returns the VBScript date literal for t, such as
+// #10/16/2026#, #10/16/2026 2:30:00 PM# or, for times on the zero
+// date, #2:30:00 PM#.
+func FormatDate(t time.Time) string {
+	y, m, d := t.Date()
+	hasTime := t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0
+	switch {
+	case !hasTime:
+		return fmt.Sprintf("#%d/%d/%d#", m, d, y)
+	case y == 1899 && m == time.December && d == 30:
+		return "#" + t.Format("3:04:05 PM") + "#"
+	}
+	return fmt.Sprintf("#%d/%d/%d %s#", m, d, y, t.Format("3:04:05 PM"))
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package vbsconv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hulo-io/vbsparser/vbsconv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	testset := []struct {
+		value, lit string
+	}{
+		{"", `""`},
+		{"abc", `"abc"`},
+		{`He said "hi"`, `"He said ""hi"""`},
+		{`"`, `""""`},
+		{"a\r\nb", "\"a\r\nb\""},
+	}
+	for _, tt := range testset {
+		assert.Equal(t, tt.lit, vbsconv.Quote(tt.value))
+		s, err := vbsconv.Unquote(tt.lit)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.value, s)
+	}
+
+	for _, lit := range []string{``, `"`, `abc`, `"a"b"`, `"abc`} {
+		_, err := vbsconv.Unquote(lit)
+		assert.ErrorIs(t, err, vbsconv.ErrSyntax, lit)
+	}
+}
+
+func TestParseInteger(t *testing.T) {
+	testset := []struct {
+		lit   string
+		value int64
+	}{
+		{"0", 0},
+		{"42", 42},
+		{"&HFF", 255},
+		{"&hff", 255},
+		{"&O17", 15},
+		{"&HFFFF", -1},
+		{"&H8000", -32768},
+		{"&HFFFF&", 65535},
+		{"&H10000", 65536},
+		{"&HFFFFFFFF", -1},
+		{"3000000000", 3000000000},
+	}
+	for _, tt := range testset {
+		i, err := vbsconv.ParseInteger(tt.lit)
+		assert.NoError(t, err, tt.lit)
+		assert.Equal(t, tt.value, i, tt.lit)
+	}
+
+	_, err := vbsconv.ParseInteger("&H100000000")
+	assert.ErrorIs(t, err, vbsconv.ErrRange)
+	assert.EqualError(t, err, `vbsconv.ParseInteger: parsing "&H100000000": value out of range`)
+	_, err = vbsconv.ParseInteger("&O8")
+	assert.ErrorIs(t, err, vbsconv.ErrSyntax)
+	_, err = vbsconv.ParseInteger("1.5")
+	assert.ErrorIs(t, err, vbsconv.ErrSyntax)
+
+	assert.Equal(t, "&HFF", vbsconv.FormatInteger(255, 16))
+	assert.Equal(t, "&HFFFF", vbsconv.FormatInteger(-1, 16))
+	assert.Equal(t, "&HFFFF&", vbsconv.FormatInteger(65535, 16))
+	assert.Equal(t, "&O17", vbsconv.FormatInteger(15, 8))
+	assert.Equal(t, "-7", vbsconv.FormatInteger(-7, 10))
+}
+
+func TestParseFloat(t *testing.T) {
+	f, err := vbsconv.ParseFloat("1.5E+10")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5e10, f)
+	f, err = vbsconv.ParseFloat(".5")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+	_, err = vbsconv.ParseFloat("1.5E")
+	assert.ErrorIs(t, err, vbsconv.ErrSyntax)
+}
+
+func TestParseDate(t *testing.T) {
+	testset := []struct {
+		lit      string
+		expected time.Time
+		format   string
+	}{
+		{"#10/16/2026#", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "#10/16/2026#"},
+		{"2026-10-16", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "#10/16/2026#"},
+		{"#10/16/2026 2:30:00 pm#", time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC), "#10/16/2026 2:30:00 PM#"},
+		{"#October 16, 2026 14:30#", time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC), "#10/16/2026 2:30:00 PM#"},
+		{"#16 Oct 2026#", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "#10/16/2026#"},
+		{"#1:30 AM#", time.Date(1899, 12, 30, 1, 30, 0, 0, time.UTC), "#1:30:00 AM#"},
+	}
+	for _, tt := range testset {
+		d, err := vbsconv.ParseDate(tt.lit)
+		assert.NoError(t, err, tt.lit)
+		assert.Equal(t, tt.expected, d, tt.lit)
+		assert.Equal(t, tt.format, vbsconv.FormatDate(d), tt.lit)
+	}
+
+	for _, lit := range []string{"#", "#10/16/2026", "#13/13/2026#", "#tomorrow#"} {
+		_, err := vbsconv.ParseDate(lit)
+		assert.ErrorIs(t, err, vbsconv.ErrSyntax, lit)
+	}
+}
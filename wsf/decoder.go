@@ -0,0 +1,248 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package wsf
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/internal/markup"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// A decoder holds the state of reading a .wsf file.
+type decoder struct {
+	fset    *token.FileSet
+	file    *token.File
+	mode    parser.Mode
+	xml     bool // set if the file starts with an <?xml?> declaration
+	scanner markup.Scanner
+	errors  scanner.ErrorList
+}
+
+func (d *decoder) init(fset *token.FileSet, filename string, src []byte, conf *parser.Config) {
+	text, origin := scanner.Decode(src, conf.Charmap)
+	d.fset = fset
+	d.file = fset.AddFile(filename, -1, len(text))
+	d.file.SetLinesForContent(text)
+	d.file.SetOffsetMap(origin)
+	d.mode = conf.Mode
+	d.xml = bytes.HasPrefix(bytes.TrimLeft(text, "\ufeff \t\r\n"), []byte("<?xml"))
+	d.scanner = markup.Scanner{Src: text, Unescape: d.xml, Error: d.error}
+}
+
+func (d *decoder) error(offs int, msg string) {
+	d.errors.Add(d.file.Position(d.file.Pos(offs)), msg)
+}
+
+func (d *decoder) next() markup.Item { return d.scanner.Next() }
+
+func (d *decoder) pos(it markup.Item) token.Pos { return d.file.Pos(it.Offs) }
+
+// skipElement skips the content of the element started by it up to
+// and including its end tag.
+func (d *decoder) skipElement(it markup.Item) {
+	d.text(it)
+}
+
+// text returns the character data of the element started by it, up to
+// and including its end tag. Nested elements are skipped.
+func (d *decoder) text(it markup.Item) string {
+	if it.Empty {
+		return ""
+	}
+	var buf strings.Builder
+	depth := 0
+	for {
+		t := d.next()
+		switch t.Kind {
+		case markup.EOF:
+			d.error(it.Offs, "expected </"+it.Name+">")
+			return buf.String()
+		case markup.Text:
+			if depth == 0 {
+				buf.WriteString(t.Text)
+			}
+		case markup.StartTag:
+			if !t.Empty {
+				depth++
+			}
+		case markup.EndTag:
+			if depth == 0 {
+				if t.Name != it.Name {
+					d.error(t.Offs, "expected </"+it.Name+">")
+					d.scanner.Offs = t.Offs // leave it to the enclosing element
+				}
+				return buf.String()
+			}
+			depth--
+		}
+	}
+}
+
+func isTrue(s string) bool {
+	return strings.EqualFold(s, "true") || s == "1" || strings.EqualFold(s, "yes")
+}
+
+func (d *decoder) parsePackage() *Package {
+	pkg := &Package{}
+	for {
+		it := d.next()
+		switch it.Kind {
+		case markup.EOF:
+			return pkg
+		case markup.StartTag:
+			switch it.Name {
+			case "package":
+				// the jobs follow
+			case "job":
+				pkg.Jobs = append(pkg.Jobs, d.parseJob(it))
+			default:
+				d.error(it.Offs, "unexpected <"+it.Name+">")
+				d.skipElement(it)
+			}
+		case markup.EndTag:
+			if it.Name != "package" {
+				d.error(it.Offs, "unexpected </"+it.Name+">")
+			}
+		}
+	}
+}
+
+func (d *decoder) parseJob(start markup.Item) *Job {
+	job := &Job{Pos: d.pos(start), ID: start.Attr("id")}
+	if start.Empty {
+		return job
+	}
+	for {
+		it := d.next()
+		switch it.Kind {
+		case markup.EOF:
+			d.error(start.Offs, "expected </job>")
+			return job
+		case markup.ProcInst:
+			if it.Name == "job" {
+				job.Debug = isTrue(it.Attr("debug"))
+			}
+		case markup.EndTag:
+			switch it.Name {
+			case "job":
+				return job
+			case "package":
+				d.error(it.Offs, "expected </job>")
+				d.scanner.Offs = it.Offs
+				return job
+			}
+			d.error(it.Offs, "unexpected </"+it.Name+">")
+		case markup.StartTag:
+			switch it.Name {
+			case "runtime":
+				job.Runtime = d.parseRuntime(it)
+			case "object":
+				job.Objects = append(job.Objects, &Object{
+					Pos:     d.pos(it),
+					ID:      it.Attr("id"),
+					ProgID:  it.Attr("progid"),
+					ClassID: it.Attr("classid"),
+					Events:  isTrue(it.Attr("events")),
+				})
+				d.skipElement(it)
+			case "reference":
+				job.References = append(job.References, &Reference{
+					Pos:     d.pos(it),
+					Object:  it.Attr("object"),
+					GUID:    it.Attr("guid"),
+					Version: it.Attr("version"),
+				})
+				d.skipElement(it)
+			case "resource":
+				job.Resources = append(job.Resources, &Resource{Pos: d.pos(it), ID: it.Attr("id"), Text: d.text(it)})
+			case "script":
+				job.Scripts = append(job.Scripts, d.parseScript(it))
+			default:
+				// e.g. <comment>
+				d.skipElement(it)
+			}
+		}
+	}
+}
+
+func (d *decoder) parseRuntime(start markup.Item) *Runtime {
+	rt := &Runtime{Pos: d.pos(start)}
+	if start.Empty {
+		return rt
+	}
+	for {
+		it := d.next()
+		switch it.Kind {
+		case markup.EOF:
+			d.error(start.Offs, "expected </runtime>")
+			return rt
+		case markup.EndTag:
+			if it.Name != "runtime" {
+				d.error(it.Offs, "expected </runtime>")
+				d.scanner.Offs = it.Offs // leave it to the enclosing element
+			}
+			return rt
+		case markup.StartTag:
+			switch it.Name {
+			case "named":
+				rt.Named = append(rt.Named, d.parseArgument(it))
+			case "unnamed":
+				rt.Unnamed = append(rt.Unnamed, d.parseArgument(it))
+			case "description":
+				rt.Description = d.text(it)
+			case "example":
+				rt.Example = d.text(it)
+			case "usage":
+				rt.Usage = d.text(it)
+			default:
+				d.skipElement(it)
+			}
+		}
+	}
+}
+
+func (d *decoder) parseArgument(it markup.Item) *Argument {
+	arg := &Argument{
+		Pos:        d.pos(it),
+		Name:       it.Attr("name"),
+		HelpString: it.Attr("helpstring"),
+		Type:       it.Attr("type"),
+		Required:   isTrue(it.Attr("required")),
+		Many:       isTrue(it.Attr("many")),
+	}
+	d.skipElement(it)
+	return arg
+}
+
+// parseScript parses a <script> element. The content of the element is
+// taken literally up to </script>, apart from CDATA sections and, in an
+// XML document, character references.
+func (d *decoder) parseScript(it markup.Item) *Script {
+	s := &Script{Pos: d.pos(it), Language: it.Attr("language"), Src: it.Attr("src")}
+	if it.Empty {
+		return s
+	}
+
+	start, end := d.scanner.RawText(it.Offs, "script")
+	lang := strings.ToLower(s.Language)
+	if (lang == "vbscript" || lang == "vbs") && len(bytes.TrimSpace(d.scanner.Src[start:end])) > 0 {
+		s.File = d.parseCode(start, end)
+	}
+	return s
+}
+
+func (d *decoder) parseCode(start, end int) *ast.File {
+	cm := markup.CDATA
+	if d.xml {
+		cm |= markup.Unescape
+	}
+	f, errs := markup.ParseCode(d.fset, d.file, d.scanner.Src, start, end, cm, d.mode)
+	d.errors = append(d.errors, errs...)
+	return f
+}
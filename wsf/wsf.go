@@ -0,0 +1,144 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package wsf implements a parser for Windows Script Files (.wsf), the
+// XML container format of Windows Script Host. It exposes the jobs of a
+// file with their objects, type library references, resources and
+// runtime arguments, and parses the inline VBScript blocks with package
+// parser.
+//
+// As Windows Script Host does, the parser only applies the XML rules for
+// character references when the file starts with an <?xml?> declaration;
+// otherwise script blocks are taken literally up to </script>. CDATA
+// sections are permitted in both cases.
+package wsf
+
+import (
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/internal/markup"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/token"
+)
+
+// A Package represents a .wsf file: one or more jobs, optionally
+// wrapped in a <package> element.
+type Package struct {
+	Jobs []*Job
+}
+
+// A Job represents a <job> element.
+type Job struct {
+	Pos        token.Pos // position of "<job"
+	ID         string
+	Debug      bool // set by <?job debug="true"?>
+	Runtime    *Runtime
+	Objects    []*Object
+	References []*Reference
+	Resources  []*Resource
+	Scripts    []*Script
+}
+
+// Script returns the first inline VBScript block of j, or nil.
+func (j *Job) Script() *Script {
+	for _, s := range j.Scripts {
+		if s.File != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// A Script represents a <script> element.
+type Script struct {
+	Pos      token.Pos // position of "<script"
+	Language string    // value of the language attribute, e.g. "VBScript"
+	Src      string    // value of the src attribute; or ""
+	File     *ast.File // parsed inline code; nil for other languages or if there is none
+}
+
+// An Object represents an <object> element, which creates an object
+// that is available to the scripts of the job.
+type Object struct {
+	Pos     token.Pos // position of "<object"
+	ID      string
+	ProgID  string
+	ClassID string
+	Events  bool
+}
+
+// A Reference represents a <reference> element, which makes the
+// constants of a type library available to the scripts of the job.
+type Reference struct {
+	Pos     token.Pos // position of "<reference"
+	Object  string    // ProgID of the type library; or ""
+	GUID    string    // or ""
+	Version string
+}
+
+// A Resource represents a <resource> element, text that scripts obtain
+// with GetResource.
+type Resource struct {
+	Pos  token.Pos // position of "<resource"
+	ID   string
+	Text string
+}
+
+// A Runtime represents the <runtime> element that documents the command
+// line arguments of a job.
+type Runtime struct {
+	Pos         token.Pos // position of "<runtime"
+	Named       []*Argument
+	Unnamed     []*Argument
+	Description string
+	Example     string
+	Usage       string
+}
+
+// An Argument represents a <named> or <unnamed> command line argument.
+type Argument struct {
+	Pos        token.Pos // position of "<named" or "<unnamed"
+	Name       string
+	HelpString string
+	Type       string // "string", "boolean" or "simple"; named arguments only
+	Required   bool
+	Many       bool // unnamed arguments only
+}
+
+// ParseFile parses a .wsf file and the inline VBScript blocks it contains.
+// The arguments have the same meaning as for parser.ParseFile; mode is
+// passed on for the script blocks.
+//
+// The positions of the elements and of the script ASTs refer to the .wsf
+// file. Each script block is parsed separately; its ast.File has a
+// token.File of its own in fset. As for decoded source in general, a
+// column counts a character reference in a script as one character.
+//
+// If the source couldn't be read, the returned package is nil and the
+// error indicates the specific failure. If the source was read but errors
+// were found in the container or in script blocks, the result is a
+// partial package and the errors are returned via a scanner.ErrorList
+// which is sorted by source position.
+func ParseFile(fset *token.FileSet, filename string, src any, mode parser.Mode) (pkg *Package, err error) {
+	return ParseFileConfig(fset, filename, src, &parser.Config{Mode: mode})
+}
+
+// ParseFileConfig is like ParseFile, with the mode taken from conf.Mode;
+// files in an ANSI code page are decoded with conf.Charmap.
+func ParseFileConfig(fset *token.FileSet, filename string, src any, conf *parser.Config) (pkg *Package, err error) {
+	if fset == nil {
+		panic("wsf.ParseFile: no token.FileSet provided (fset == nil)")
+	}
+
+	text, err := markup.ReadSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var d decoder
+	d.init(fset, filename, text, conf)
+	pkg = d.parsePackage()
+
+	d.errors.Sort()
+	return pkg, d.errors.Err()
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Hulo Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package wsf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hulo-io/vbsparser/ast"
+	"github.com/hulo-io/vbsparser/parser"
+	"github.com/hulo-io/vbsparser/scanner"
+	"github.com/hulo-io/vbsparser/token"
+	"github.com/hulo-io/vbsparser/wsf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFile(t *testing.T) {
+	const src = `<package>
+<!-- backup jobs -->
+<job id="Backup">
+<?job debug="true"?>
+<runtime>
+  <description>Copies files.</description>
+  <named name="src" helpstring="Source folder" type="string" required="true"/>
+  <unnamed name="files" helpstring="Files" many="true"/>
+  <example>backup.wsf /src:C:\data a.txt</example>
+</runtime>
+<object id="fso" progid="Scripting.FileSystemObject"/>
+<reference object="Scripting.FileSystemObject" version="1.0"/>
+<resource id="greeting">Hello</resource>
+<script language="VBScript" src="lib.vbs"/>
+<script language="JScript">var x = 1 < 2;</script>
+<script language="VBScript">
+If a < b Then
+  WScript.Echo GetResource("greeting")
+End If
+</script>
+</job>
+<job id="Empty"></job>
+</package>`
+	fset := token.NewFileSet()
+	pkg, err := wsf.ParseFile(fset, "backup.wsf", src, 0)
+	assert.NoError(t, err)
+	assert.Len(t, pkg.Jobs, 2)
+
+	job := pkg.Jobs[0]
+	assert.Equal(t, "Backup", job.ID)
+	assert.True(t, job.Debug)
+	assert.Equal(t, token.Position{Filename: "backup.wsf", Offset: strings.Index(src, "<job"), Line: 3, Column: 1}, fset.Position(job.Pos))
+
+	rt := job.Runtime
+	assert.Equal(t, "Copies files.", rt.Description)
+	assert.Equal(t, `backup.wsf /src:C:\data a.txt`, rt.Example)
+	assert.Equal(t, []*wsf.Argument{{Pos: rt.Named[0].Pos, Name: "src", HelpString: "Source folder", Type: "string", Required: true}}, rt.Named)
+	assert.Equal(t, []*wsf.Argument{{Pos: rt.Unnamed[0].Pos, Name: "files", HelpString: "Files", Many: true}}, rt.Unnamed)
+
+	assert.Equal(t, []*wsf.Object{{Pos: job.Objects[0].Pos, ID: "fso", ProgID: "Scripting.FileSystemObject"}}, job.Objects)
+	assert.Equal(t, []*wsf.Reference{{Pos: job.References[0].Pos, Object: "Scripting.FileSystemObject", Version: "1.0"}}, job.References)
+	assert.Equal(t, []*wsf.Resource{{Pos: job.Resources[0].Pos, ID: "greeting", Text: "Hello"}}, job.Resources)
+
+	assert.Len(t, job.Scripts, 3)
+	assert.Equal(t, "lib.vbs", job.Scripts[0].Src)
+	assert.Nil(t, job.Scripts[0].File)
+	assert.Equal(t, "JScript", job.Scripts[1].Language)
+	assert.Nil(t, job.Scripts[1].File)
+	assert.Same(t, job.Scripts[2], job.Script())
+
+	f := job.Script().File
+	assert.Equal(t, "If a < b Then\n  WScript.Echo GetResource(\"greeting\")\nEnd If\n", ast.String(f))
+
+	// positions refer to the .wsf file
+	call := f.Stmts[0].(*ast.IfStmt).Body.List[0].(*ast.ExprStmt).X
+	assert.Equal(t, token.Position{Filename: "backup.wsf", Offset: strings.Index(src, "WScript"), Line: 18, Column: 3}, fset.Position(call.Pos()))
+
+	assert.Equal(t, "Empty", pkg.Jobs[1].ID)
+	assert.Nil(t, pkg.Jobs[1].Script())
+}
+
+func TestParseFileXML(t *testing.T) {
+	const src = `<?xml version="1.0"?>
+<job>
+<script language="VBScript"><![CDATA[
+x = 1 < 2
+]]></script>
+<script language="VBScript">
+y = a &lt; &quot;b&quot;
+</script>
+</job>`
+	fset := token.NewFileSet()
+	pkg, err := wsf.ParseFile(fset, "a.wsf", src, 0)
+	assert.NoError(t, err)
+
+	// a single job without <package>
+	job := pkg.Jobs[0]
+	assert.Equal(t, "x = 1 < 2\n", ast.String(job.Scripts[0].File))
+	assert.Equal(t, "y = a < \"b\"\n", ast.String(job.Scripts[1].File))
+
+	// character references are mapped back to the file
+	assign := job.Scripts[1].File.Stmts[0].(*ast.AssignStmt)
+	assert.Equal(t, token.Position{Filename: "a.wsf", Offset: strings.Index(src, "&quot;"), Line: 7, Column: 9}, fset.Position(assign.Rhs.(*ast.BinaryExpr).Y.Pos()))
+}
+
+func TestParseFileConfig(t *testing.T) {
+	// "x = "ж"" in a code page that maps 0xE6 to "ж", as Windows-1251 does
+	var cm scanner.Charmap
+	copy(cm[:], scanner.Windows1252[:])
+	cm[0xE6-0x80] = 'ж'
+	src := "<job>\n<script language=\"VBScript\">x = \"\xe6\"</script>\n</job>"
+
+	fset := token.NewFileSet()
+	pkg, err := wsf.ParseFileConfig(fset, "a.wsf", src, &parser.Config{Charmap: &cm})
+	assert.NoError(t, err)
+	assign := pkg.Jobs[0].Script().File.Stmts[0].(*ast.AssignStmt)
+	assert.Equal(t, "ж", assign.Rhs.(*ast.BasicLit).Value)
+	assert.Equal(t, token.Position{Filename: "a.wsf", Offset: strings.Index(src, "x ="), Line: 2, Column: 29}, fset.Position(assign.Pos()))
+}
+
+func TestParseFileErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"<job>\n<script language=\"VBScript\">\nx = \n</script>\n</job>": "a.wsf:3:5: expected operand, found newline",
+		"<job>\n<script language=\"VBScript\">\nx = 1\n":                 "a.wsf:1:1: expected </job> (and 1 more errors)",
+		"<job>\n<object id=\"o\"/>":                                      "a.wsf:1:1: expected </job>",
+		"<job><runtime></job>":                                           "a.wsf:1:15: expected </runtime>",
+		"<jobs></jobs>":                                                  "a.wsf:1:1: unexpected <jobs>",
+	} {
+		pkg, err := wsf.ParseFile(token.NewFileSet(), "a.wsf", src, 0)
+		assert.NotNil(t, pkg, src)
+		assert.EqualError(t, err, want, src)
+	}
+}